@@ -0,0 +1,224 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"hyperlane/config"
+	"hyperlane/jobs"
+	"hyperlane/logger"
+	"hyperlane/metrics"
+	"hyperlane/middlewares"
+	"hyperlane/models"
+	"hyperlane/ratelimit"
+	"hyperlane/routes"
+	"hyperlane/storage"
+	"hyperlane/utils"
+	"hyperlane/views"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// App 持有启动后需要管理生命周期的资源（数据库、路由、HTTP 服务、定时任务），由 main 显式构建。
+// 数据库、日志、JWT 和游标密钥、存储、浏览量缓冲、限流存储仍是进程级单例，由 New 按顺序初始化，
+// 包导入时不再有副作用，但 models、controllers 仍通过这些单例访问依赖，并没有做依赖注入
+type App struct {
+	DB        *gorm.DB
+	Router    *gin.Engine
+	Server    *http.Server
+	Scheduler *cron.Cron
+
+	stopJobs  context.CancelFunc
+	stopViews context.CancelFunc
+	viewsDone chan struct{}
+}
+
+// New 按顺序完成配置加载、日志、数据库、模型、各单例和路由的初始化，任一步失败都返回错误
+func New(configPath string) (*App, error) {
+	if err := config.Load(configPath); err != nil {
+		return nil, err
+	}
+
+	// 初始化日志
+	logger.Init(logger.Options{
+		File:       viper.GetString("log.file"),
+		Level:      viper.GetString("log.level"),
+		MaxSize:    viper.GetInt("log.maxSize"),
+		MaxAge:     viper.GetInt("log.maxAge"),
+		MaxBackups: viper.GetInt("log.maxBackups"),
+		Compress:   viper.GetBool("log.compress"),
+	})
+
+	db, err := config.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.Info("Database connection established")
+
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("metrics plugin: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := metrics.RegisterDB(sqlDB); err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+
+	if err := migrateOnStart(db); err != nil {
+		return nil, err
+	}
+
+	models.SetDB(db)
+	if err := models.Seed(context.Background()); err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+
+	utils.InitJWT(viper.GetString("jwt.secret"))
+	cursorSecret := viper.GetString("pagination.cursorSecret")
+	if cursorSecret == "" {
+		cursorSecret = viper.GetString("jwt.secret")
+	}
+	utils.InitCursor(cursorSecret)
+	utils.InitValidator()
+
+	if err := storage.Init(); err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	views.Init()
+
+	// 访问日志和 panic 堆栈都写入 logrus，不再使用 gin 默认输出到 stdout 的 Logger
+	r := gin.New()
+	if err := middlewares.TrustProxies(r, viper.GetStringSlice("server.trustedProxies")); err != nil {
+		return nil, err
+	}
+	r.Use(
+		middlewares.RequestID(),
+		middlewares.LoggerMiddleware(),
+		metrics.Middleware(),
+		gin.RecoveryWithWriter(logger.Log.WriterLevel(logrus.ErrorLevel)),
+		middlewares.ErrorHandler(),
+	)
+	corsPolicy, corsOverrides, err := middlewares.LoadCorsConfig()
+	if err != nil {
+		return nil, err
+	}
+	cors, err := middlewares.Cors(corsPolicy, corsOverrides...)
+	if err != nil {
+		return nil, err
+	}
+	r.Use(cors)
+
+	if viper.GetBool("rateLimit.enabled") {
+		if err := ratelimit.Init(db); err != nil {
+			return nil, err
+		}
+		rules, err := ratelimit.LoadRules()
+		if err != nil {
+			return nil, err
+		}
+		r.Use(middlewares.RateLimit(ratelimit.Default(), rules))
+	}
+	routes.SetupRouter(r)
+
+	// 本地存储的文件由服务自身提供访问
+	if local, ok := storage.Default().(*storage.Local); ok {
+		r.Static(storage.LocalRoute, local.Dir())
+	}
+
+	return &App{
+		DB:     db,
+		Router: r,
+		Server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", viper.GetInt("server.port")),
+			Handler: r,
+		},
+	}, nil
+}
+
+// Run 启动定时任务和 HTTP 服务，收到 SIGINT/SIGTERM 后等待进行中的请求处理完再退出
+func (a *App) Run() error {
+	// 启动定时任务，停机时通过 stopJobs 取消正在执行的任务
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	a.stopJobs = stopJobs
+	a.Scheduler = jobs.Start(jobsCtx)
+
+	// 定期写入缓冲的浏览量
+	viewsCtx, stopViews := context.WithCancel(context.Background())
+	a.stopViews, a.viewsDone = stopViews, make(chan struct{})
+	go func() {
+		views.Default().Run(viewsCtx, viper.GetDuration("views.flushInterval"))
+		close(a.viewsDone)
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Log.Infof("Server listening on %s", a.Server.Addr)
+		if err := a.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-errCh:
+		a.shutdown()
+		return err
+	case sig := <-quit:
+		logger.Log.Infof("Received %s, shutting down", sig)
+	}
+
+	return a.shutdown()
+}
+
+func (a *App) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("server.shutdownTimeout"))
+	defer cancel()
+
+	err := a.Server.Shutdown(ctx)
+	if err != nil {
+		logger.Log.Errorf("Server shutdown: %v", err)
+	}
+
+	// 停止调度并取消正在执行的任务，等待它们记录结果后退出
+	if a.Scheduler != nil {
+		stopped := a.Scheduler.Stop()
+		a.stopJobs()
+		select {
+		case <-stopped.Done():
+		case <-ctx.Done():
+			logger.Log.Warn("Timed out waiting for running jobs")
+		}
+	}
+
+	// 关闭数据库前写入剩余的浏览量
+	if a.stopViews != nil {
+		a.stopViews()
+		select {
+		case <-a.viewsDone:
+		case <-ctx.Done():
+			logger.Log.Warn("Timed out flushing view counts")
+		}
+	}
+
+	if sqlDB, dbErr := a.DB.DB(); dbErr == nil {
+		sqlDB.Close()
+	}
+
+	logger.Log.Info("Server exited")
+	return err
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"hyperlane/config"
+	"hyperlane/logger"
+	"hyperlane/migrations"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// 启动时检查是否有未执行的迁移，开启 database.migrateOnStart 时自动执行，否则拒绝启动
+func migrateOnStart(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if viper.GetBool("database.migrateOnStart") {
+		applied, err := migrations.Up(ctx, sqlDB)
+		for _, m := range applied {
+			logger.Log.Infof("Applied migration %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+		return nil
+	}
+
+	pending, err := migrations.Pending(ctx, sqlDB)
+	if err != nil {
+		return fmt.Errorf("check migrations: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migrations (first %04d_%s), run `hyperlane migrate up` first",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+// Migrate 执行 migrate 子命令：up 执行全部未执行的迁移，down [n] 回滚最近 n 个（默认 1），status 查看执行状态
+func Migrate(configPath string, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [n] | status")
+	}
+	if err := config.Load(configPath); err != nil {
+		return err
+	}
+
+	db, err := config.ConnectDB()
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(ctx, sqlDB)
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("invalid rollback count: %s", args[1])
+			}
+		}
+		reverted, err := migrations.Down(ctx, sqlDB, n)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		status, err := migrations.Statuses(ctx, sqlDB)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+}
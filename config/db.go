@@ -2,16 +2,14 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"hyperlane/logger"
 
 	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
-func ConnectDB() {
+func ConnectDB() (*gorm.DB, error) {
 	dbHost := viper.GetString("database.host")
 	dbPort := viper.GetString("database.port")
 	dbUser := viper.GetString("database.user")
@@ -22,10 +20,11 @@ func ConnectDB() {
 	pgi := "host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Shanghai"
 	dsn := fmt.Sprintf(pgi, dbHost, dbUser, dbPassword, dbName, dbPort, dbSsl)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.NewGormLogger(viper.GetDuration("database.slowThreshold")),
+	})
 	if err != nil {
-		log.Fatal("Failed to connect to the database:", err)
+		return nil, fmt.Errorf("connect database: %w", err)
 	}
-	DB = db
-	log.Println("Database connection established")
+	return db, nil
 }
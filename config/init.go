@@ -6,14 +6,53 @@ import (
 	"github.com/spf13/viper"
 )
 
-func init() {
-	viper.SetConfigName("config") // 配置文件名称（不带扩展名）
-	viper.SetConfigType("yaml")   // 配置文件格式
-	viper.AddConfigPath("./")     // 配置文件路径
+// Load 读取配置文件，path 为空时从当前目录查找 config.yaml
+func Load(path string) error {
+	if path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.SetConfigName("config") // 配置文件名称（不带扩展名）
+		viper.SetConfigType("yaml")   // 配置文件格式
+		viper.AddConfigPath("./")     // 配置文件路径
+	}
+
+	viper.SetDefault("server.port", 8080)
+	viper.SetDefault("server.shutdownTimeout", "15s")
+	viper.SetDefault("metrics.allowedIPs", []string{"127.0.0.1", "::1"})
+	viper.SetDefault("log.maxSize", 100)
+	viper.SetDefault("log.maxAge", 30)
+	viper.SetDefault("log.maxBackups", 10)
+	viper.SetDefault("database.slowThreshold", "200ms")
+	viper.SetDefault("calendar.timezone", "UTC")
+	viper.SetDefault("cors.allowedOrigins", []string{"https://www.hyperlane.cc", "https://hyperlane.cc"})
+	viper.SetDefault("cors.allowedHeaders", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-Request-ID"})
+	viper.SetDefault("cors.exposedHeaders", []string{"Content-Length", "Content-Type", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-Request-ID"})
+	viper.SetDefault("cors.allowCredentials", true)
+	viper.SetDefault("cors.maxAge", "12h")
+	viper.SetDefault("rateLimit.enabled", true)
+	viper.SetDefault("rateLimit.store", "memory")
+	viper.SetDefault("rateLimit.default.limit", 300)
+	viper.SetDefault("rateLimit.default.period", "1m")
+	viper.SetDefault("feed.mixRatio", 0.7)
+	viper.SetDefault("feed.halfLife", "24h")
+	viper.SetDefault("feed.window", "720h")
+	viper.SetDefault("feed.snapshotTTL", "1h")
+	viper.SetDefault("feed.weights.views", 0.1)
+	viper.SetDefault("feed.weights.likes", 1)
+	viper.SetDefault("feed.weights.favorites", 2)
+	viper.SetDefault("views.dedupWindow", "30m")
+	viper.SetDefault("views.flushInterval", "10s")
+	viper.SetDefault("moderation.claimTTL", "30m")
+	viper.SetDefault("moderation.slaTarget", "48h")
+	viper.SetDefault("uploads.maxSize", 5<<20)
+	viper.SetDefault("uploads.minWidth", 16)
+	viper.SetDefault("uploads.minHeight", 16)
+	viper.SetDefault("uploads.maxWidth", 8000)
+	viper.SetDefault("uploads.maxHeight", 8000)
+	viper.SetDefault("uploads.thumbSize", 320)
 
 	if err := viper.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("Fatal error config file: %w \n", err))
+		return fmt.Errorf("read config file: %w", err)
 	}
-
-	ConnectDB()
+	return nil
 }
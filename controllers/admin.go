@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"hyperlane/models"
+	"hyperlane/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListPermissions(c *gin.Context) {
+	perms, err := models.ListPermissions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "query success", perms)
+}
+
+func ListRoles(c *gin.Context) {
+	roles, err := models.ListRoles(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "query success", roles)
+}
+
+func CreateRole(c *gin.Context) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	role := models.Role{Name: req.Name, Description: req.Description}
+	if err := models.CreateRole(c.Request.Context(), c.GetUint("uid"), &role); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "create success", role)
+}
+
+func UpdateRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	role, err := models.UpdateRole(c.Request.Context(), c.GetUint("uid"), uint(id), req.Name, req.Description)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "success", role)
+}
+
+func ListPermissionGroups(c *gin.Context) {
+	groups, err := models.ListPermissionGroups(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "query success", groups)
+}
+
+func CreatePermissionGroup(c *gin.Context) {
+	var req PermissionGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	group := models.PermissionGroup{Name: req.Name, Description: req.Description}
+	if err := models.CreatePermissionGroup(c.Request.Context(), c.GetUint("uid"), &group); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "create success", group)
+}
+
+func UpdatePermissionGroup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var req PermissionGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	group, err := models.UpdatePermissionGroup(c.Request.Context(), c.GetUint("uid"), uint(id), req.Name, req.Description)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "success", group)
+}
+
+// 角色权限：POST 关联，DELETE 取消关联
+func SetRolePermission(attach bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleId, err1 := strconv.Atoi(c.Param("id"))
+		permId, err2 := strconv.Atoi(c.Param("permission_id"))
+		if err1 != nil || err2 != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		if err := models.SetRolePermission(c.Request.Context(), c.GetUint("uid"), uint(roleId), uint(permId), attach); err != nil {
+			c.Error(err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "success", nil)
+	}
+}
+
+// 角色权限组：POST 关联，DELETE 取消关联
+func SetRolePermissionGroup(attach bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleId, err1 := strconv.Atoi(c.Param("id"))
+		groupId, err2 := strconv.Atoi(c.Param("group_id"))
+		if err1 != nil || err2 != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		if err := models.SetRolePermissionGroup(c.Request.Context(), c.GetUint("uid"), uint(roleId), uint(groupId), attach); err != nil {
+			c.Error(err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "success", nil)
+	}
+}
+
+// 权限组权限：POST 关联，DELETE 取消关联
+func SetPermissionGroupPermission(attach bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupId, err1 := strconv.Atoi(c.Param("id"))
+		permId, err2 := strconv.Atoi(c.Param("permission_id"))
+		if err1 != nil || err2 != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		if err := models.SetPermissionGroupPermission(c.Request.Context(), c.GetUint("uid"), uint(groupId), uint(permId), attach); err != nil {
+			c.Error(err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "success", nil)
+	}
+}
+
+func AssignUserRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := models.AssignUserRole(c.Request.Context(), c.GetUint("uid"), uint(id), req.RoleId); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "success", nil)
+}
+
+func QueryAuditLogs(c *gin.Context) {
+	actorId, _ := strconv.Atoi(c.Query("actor_id"))
+	targetId, _ := strconv.Atoi(c.Query("target_id"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	filter := models.AuditLogFilter{
+		ActorId:    uint(actorId),
+		TargetType: c.Query("target_type"),
+		TargetId:   uint(targetId),
+		Page:       page,
+		PageSize:   pageSize,
+	}
+
+	logs, total, err := models.QueryAuditLogs(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var response = QueryAuditLogsResponse{
+		Logs:     logs,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
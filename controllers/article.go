@@ -2,10 +2,10 @@ package controllers
 
 import (
 	"hyperlane/models"
+	"hyperlane/policy"
 	"hyperlane/utils"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,7 +14,7 @@ func CreateArticle(c *gin.Context) {
 	var req CreateArticleRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
@@ -39,9 +39,12 @@ func CreateArticle(c *gin.Context) {
 
 	userId, _ := uid.(uint)
 	article.PublisherId = uint(userId)
+	if req.Draft {
+		article.PublishStatus = models.PublishStatusDraft
+	}
 	// 创建数据库记录
-	if err := article.Create(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := article.Create(c.Request.Context()); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -59,11 +62,16 @@ func GetArticle(c *gin.Context) {
 	var article models.Article
 	article.ID = uint(id)
 
-	if err = article.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Article", nil)
+	if err = article.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
+	// 作者和审核人预览未发布的博客不计浏览量
+	if article.PublishStatus == models.PublishStatusPublished && recordView(c, models.CommentTargetBlog, article.ID) {
+		article.ViewCount++
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "success", article)
 }
 
@@ -79,6 +87,12 @@ func QueryArticles(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "6"))
 
+	cursor, err := listCursor(c, models.CursorScopeArticles, order == "desc")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	filter := models.ArticleFilter{
 		Keyword:       keyword,
 		Tag:           tag,
@@ -89,18 +103,23 @@ func QueryArticles(c *gin.Context) {
 		OrderDesc:     order == "desc",
 		Page:          page,
 		PageSize:      pageSize,
+		Cursor:        cursor,
+		SkipTotal:     skipTotal(c, cursor),
 	}
 
-	articles, total, err := models.QueryArticles(filter)
+	articles, total, err := models.QueryArticles(c.Request.Context(), filter)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
 	var response = QueryArticlesResponse{
 		Page:     page,
 		PageSize: pageSize,
-		Total:    total,
+		Total:    totalOrNil(total),
+	}
+	if cursor != nil {
+		response.NextCursor = models.NextCursor(cursor, articles, pageSize)
 	}
 	if category == "blog" {
 		response.Blogs = articles
@@ -121,24 +140,16 @@ func DeleteArticle(c *gin.Context) {
 	var article models.Article
 	article.ID = uint(id)
 
-	if err = article.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid article", nil)
+	if err = article.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
-	uid, ok := c.Get("uid")
-	if !ok {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
-		return
-	}
-
-	userId, _ := uid.(uint)
-	if article.PublisherId != userId {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "not author", nil)
+	if !authorize(c, policy.Blog, policy.Delete, article.PublisherId) {
 		return
 	}
 
-	if err := article.Delete(); err != nil {
+	if err := article.Delete(c.Request.Context()); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete article", nil)
 		return
 	}
@@ -155,83 +166,40 @@ func UpdateArticle(c *gin.Context) {
 
 	var req UpdateArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", nil)
+		c.Error(err)
 		return
 	}
 
 	var article models.Article
 	article.ID = uint(id)
 
-	if err = article.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid article", nil)
+	if err = article.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
-	uid, ok := c.Get("uid")
-	if !ok {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
-		return
-	}
-
-	userId, _ := uid.(uint)
-	if article.PublisherId != userId {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "not author", nil)
+	if !authorize(c, policy.Blog, policy.Update, article.PublisherId) {
 		return
 	}
+	userId := c.GetUint("uid")
 
-	article.Title = req.Title
-	article.Description = req.Desc
-	article.Content = req.Content
-	article.Category = req.Category
-	article.SourceLink = req.SourceLink
-	article.CoverImg = req.CoverImg
-	article.Tags = req.Tags
-	article.Author = req.Author
-
-	article.PublishStatus = 1 // 更新后需要重新审核
-
-	if err := article.Update(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update article", nil)
-		return
+	// 每次修改生成新的修订；已发布的博客在修订审核通过前保持原内容
+	content := models.ArticleContent{
+		Title:       req.Title,
+		Description: req.Desc,
+		Content:     req.Content,
+		Category:    req.Category,
+		SourceLink:  req.SourceLink,
+		SourceType:  article.SourceType,
+		CoverImg:    req.CoverImg,
+		Tags:        req.Tags,
+		Author:      req.Author,
+		Translator:  article.Translator,
 	}
-	utils.SuccessResponse(c, http.StatusOK, "success", article)
-}
-
-func UpdateArticlePublishStatus(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+	updated, _, err := models.SaveArticleRevision(c.Request.Context(), article.ID, userId, content)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
-		return
-	}
-
-	var req UpdateBlogPublishStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", nil)
+		c.Error(err)
 		return
 	}
-
-	var article models.Article
-	article.ID = uint(id)
-
-	if err = article.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid artcile", nil)
-		return
-	}
-
-	if article.PublishStatus != 1 && article.PublishStatus != 2 {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid status", nil)
-		return
-	}
-
-	// TODO: 2 -> 1 ?
-	now := time.Now()
-	article.PublishStatus = req.PublishStatus
-	article.PublishTime = &now
-
-	if err := article.Update(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update article", nil)
-		return
-	}
-	utils.SuccessResponse(c, http.StatusOK, "success", article)
+	utils.SuccessResponse(c, http.StatusOK, "success", updated)
 }
@@ -1,13 +1,18 @@
 package controllers
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hyperlane/logger"
+	"hyperlane/metrics"
 	"hyperlane/models"
 	"hyperlane/utils"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -21,13 +26,15 @@ func HandleLogin(c *gin.Context) {
 		return
 	}
 
-	loginResp, err := processOAuthLogin(req.Code)
+	loginResp, err := processOAuthLogin(c.Request.Context(), req.Code, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
 		logger.Log.Errorf("Login failed: %v", err)
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
+	metrics.Logins.WithLabelValues("success").Inc()
 	utils.SuccessResponse(c, http.StatusOK, "success", loginResp)
 }
 
@@ -46,19 +53,81 @@ func HandleOAuthCallback(c *gin.Context) {
 		return
 	}
 
-	loginResp, err := processOAuthLogin(code)
+	user, err := oauthUser(c.Request.Context(), code)
 	if err != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
 		logger.Log.Errorf("OAuth login failed: %v", err)
 		c.Redirect(http.StatusFound, fmt.Sprintf("%s/login?error=login_failed", frontendUrl))
 		return
 	}
 
-	// 成功：重定向到首页并带上 token
-	c.Redirect(http.StatusFound, fmt.Sprintf("%s/?token=%s", frontendUrl, loginResp.Token))
+	// 令牌不能出现在 URL 中（会进入浏览器历史、Referer 和访问日志），
+	// 只带一次性登录码，由前端 POST /v1/auth/exchange 换取令牌
+	loginCode, err := utils.GenerateLoginCode()
+	if err == nil {
+		err = models.CreateLoginCode(c.Request.Context(), &models.LoginCode{
+			CodeHash:  utils.HashToken(loginCode),
+			UserId:    user.ID,
+			ExpiresAt: time.Now().Add(utils.LoginCodeTTL()),
+		})
+	}
+	if err != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
+		logger.Log.Errorf("create login code failed: %v", err)
+		c.Redirect(http.StatusFound, fmt.Sprintf("%s/login?error=login_failed", frontendUrl))
+		return
+	}
+
+	metrics.Logins.WithLabelValues("success").Inc()
+	c.Redirect(http.StatusFound, frontendUrl+"/login?"+url.Values{"code": {loginCode}}.Encode())
+}
+
+// HandleLoginCodeExchange 用 OAuth 回调得到的一次性登录码换取令牌
+func HandleLoginCodeExchange(c *gin.Context) {
+	var req SignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userId, err := models.ConsumeLoginCode(c.Request.Context(), utils.HashToken(req.Code))
+	if err != nil {
+		if errors.Is(err, models.ErrLoginCodeInvalid) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Please log in to continue!", nil)
+			return
+		}
+		logger.Log.Errorf("consume login code failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	user, err := models.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Please log in to continue!", nil)
+		return
+	}
+
+	loginResp, err := completeLogin(c.Request.Context(), user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		logger.Log.Errorf("Login failed: %v", err)
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", loginResp)
 }
 
 // processOAuthLogin 封装通用的 OAuth 登录逻辑
-func processOAuthLogin(code string) (*LoginResponse, error) {
+func processOAuthLogin(ctx context.Context, code, userAgent, ip string) (*LoginResponse, error) {
+	user, err := oauthUser(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return completeLogin(ctx, user, userAgent, ip)
+}
+
+// oauthUser 用 OAuth code 换取 OpenBuild 用户信息，并创建或更新本地用户
+func oauthUser(ctx context.Context, code string) (*models.User, error) {
 	var accessRequest AccessTokenRequest
 	accessRequest.ClientId = viper.GetString("oauth.clientId")
 	accessRequest.ClientSecret = viper.GetString("oauth.clientSecret")
@@ -78,13 +147,13 @@ func processOAuthLogin(code string) (*LoginResponse, error) {
 	header["Authorization"] = "Basic " + basicAuth
 	reqArgs.Headers = header
 
-	logger.Log.Infof("Sending OAuth request:  %s", reqArgs)
+	logger.Log.Debugf("Sending OAuth request: %s", reqArgs.URL)
 	result, err := utils.SendHTTPRequest(reqArgs)
 	if err != nil {
 		return nil, fmt.Errorf("network error")
 	}
 
-	logger.Log.Infof("OAuth response: %s", result)
+	logger.Log.Debugf("OAuth response: %s", logger.Redact(result))
 	// Parse OpenBuild OAuth response
 
 	var tokenResp struct {
@@ -104,19 +173,18 @@ func processOAuthLogin(code string) (*LoginResponse, error) {
 	header["Authorization"] = fmt.Sprintf("Bearer %s", accessToken)
 	reqArgs.Headers = header
 
-	logger.Log.Infof("Sending OpenBuild user request:  %s", reqArgs)
+	logger.Log.Debugf("Sending OpenBuild user request: %s", reqArgs.URL)
 	userResult, err := utils.SendHTTPRequest(reqArgs)
 	if err != nil {
 		return nil, fmt.Errorf("network error")
 	}
-	logger.Log.Infof("OpenBuild user response: %s", userResult)
+	logger.Log.Debugf("OpenBuild user response: %s", logger.Redact(userResult))
 	// Parse OpenBuild User response
 	var openBuildUser GetUserResponse
 	err = json.Unmarshal([]byte(userResult), &openBuildUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse user data")
 	}
-	logger.Log.Infof("OpenBuild user data: %+v", openBuildUser)
 
 	var user *models.User
 	// Use OpenBuild ID as Uid
@@ -124,13 +192,13 @@ func processOAuthLogin(code string) (*LoginResponse, error) {
 
 	logger.Log.Infof("Using OpenBuild user ID: %d", userId)
 
-	user, err = models.GetUserByUid(userId)
+	user, err = models.GetUserByUid(ctx, userId)
 	if err == nil {
 		// Update existing user
 		user.Uid = userId
 		user.Email = openBuildUser.Data.Email
 		user.Github = openBuildUser.Data.Github
-		err = models.UpdateUser(user)
+		err = models.UpdateUser(ctx, user)
 	} else {
 		// Create new user
 		var u models.User
@@ -140,31 +208,164 @@ func processOAuthLogin(code string) (*LoginResponse, error) {
 		u.Username = openBuildUser.Data.UserName
 		u.Github = openBuildUser.Data.Github
 		user = &u
-		err = models.CreateUser(user)
+		err = models.CreateUser(ctx, user)
 	}
 
-	logger.Log.Infof("User info: %+v", user)
-
 	if err != nil {
 		return nil, fmt.Errorf("failed to save user")
 	}
 
+	return user, nil
+}
+
+// completeLogin 为已认证的用户签发令牌（登录时新建 family）
+func completeLogin(ctx context.Context, user *models.User, userAgent, ip string) (*LoginResponse, error) {
 	// TODO: gocache?
-	perms, err := models.GetUserWithPermissions(user.ID)
+	perms, err := models.GetUserWithPermissions(ctx, user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get permissions")
 	}
 
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Avatar, user.Username, user.Github, perms)
+	familyId, err := utils.GenerateFamilyId()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token")
 	}
 
-	logger.Log.Infof("Generated token: %s", token)
+	tokens, err := issueTokens(ctx, user, perms, familyId, userAgent, ip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token")
+	}
+
+	logger.Log.Infof("Issued tokens for user %d", user.ID)
 
 	return &LoginResponse{
-		User:        *user,
-		Permissions: perms,
-		Token:       token,
+		User:          *user,
+		Permissions:   perms,
+		TokenResponse: *tokens,
+	}, nil
+}
+
+// issueTokens 签发 access token 和一个新的刷新令牌（登录时新建 family）
+func issueTokens(ctx context.Context, user *models.User, perms []string, familyId, userAgent, ip string) (*TokenResponse, error) {
+	token, err := utils.GenerateToken(user.ID, user.Email, user.Avatar, user.Username, user.Github, perms, user.TokenVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	rt := models.RefreshToken{
+		UserId:    user.ID,
+		FamilyId:  familyId,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL()),
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+	if err := models.CreateRefreshToken(ctx, &rt); err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
 	}, nil
 }
+
+// 用刷新令牌换取新的 access token，刷新令牌同时轮换
+func HandleRefresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	newRefreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to generate token", nil)
+		return
+	}
+
+	rt, err := models.RotateRefreshToken(c.Request.Context(),
+		utils.HashToken(req.RefreshToken),
+		utils.HashToken(newRefreshToken),
+		utils.RefreshTokenTTL(),
+		c.Request.UserAgent(),
+		c.ClientIP(),
+	)
+	if err != nil {
+		if errors.Is(err, models.ErrRefreshTokenReused) {
+			logger.Log.Warnf("refresh token reuse detected from %s", c.ClientIP())
+		}
+		if errors.Is(err, models.ErrRefreshTokenInvalid) || errors.Is(err, models.ErrRefreshTokenReused) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Please log in to continue!", nil)
+			return
+		}
+		logger.Log.Errorf("refresh token failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	user, err := models.GetUserById(c.Request.Context(), rt.UserId)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Please log in to continue!", nil)
+		return
+	}
+
+	perms, err := models.GetUserWithPermissions(c.Request.Context(), user.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to get permissions", nil)
+		return
+	}
+
+	token, err := utils.GenerateToken(user.ID, user.Email, user.Avatar, user.Username, user.Github, perms, user.TokenVersion)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to generate token", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", TokenResponse{
+		Token:        token,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+	})
+}
+
+// 退出登录：吊销刷新令牌所在 family，并吊销当前 access token
+func HandleLogout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	userId := c.GetUint("uid")
+	if err := models.RevokeRefreshToken(c.Request.Context(), userId, utils.HashToken(req.RefreshToken)); err != nil && !errors.Is(err, models.ErrRefreshTokenInvalid) {
+		logger.Log.Errorf("revoke refresh token failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	if err := models.RevokeAccessToken(c.Request.Context(), c.GetString("jti"), userId, c.GetTime("exp")); err != nil {
+		logger.Log.Errorf("revoke access token failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "logout success", nil)
+}
+
+// 退出所有设备
+func HandleLogoutAll(c *gin.Context) {
+	if err := models.LogoutEverywhere(c.Request.Context(), c.GetUint("uid")); err != nil {
+		logger.Log.Errorf("logout everywhere failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "logout success", nil)
+}
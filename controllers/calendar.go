@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"fmt"
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+const (
+	calendarProdID      = "-//Hyperlane//Events//EN"
+	calendarContentType = "text/calendar; charset=utf-8"
+	calendarLimit       = 500                 // 订阅源最多输出的活动数
+	calendarHistory     = 90 * 24 * time.Hour // 订阅源保留已结束活动的时长
+)
+
+// EventCalendar 已发布活动的日历订阅源，支持 event_type、event_mode、tag、location 过滤
+func EventCalendar(c *gin.Context) {
+	endAfter := time.Now().Add(-calendarHistory)
+	events, _, err := models.QueryEvents(c.Request.Context(), models.EventFilter{
+		Tag:           c.Query("tag"),
+		Location:      c.Query("location"),
+		EventMode:     c.Query("event_mode"),
+		EventType:     c.Query("event_type"),
+		Status:        3, // 不按活动状态过滤
+		PublishStatus: int(models.PublishStatusPublished),
+		EndAfter:      &endAfter,
+		Page:          1,
+		PageSize:      calendarLimit,
+		SkipTotal:     true,
+	})
+	if err != nil {
+		logger.Log.Errorf("query event calendar: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to load calendar", nil)
+		return
+	}
+
+	cal := newEventCalendar("Hyperlane Events")
+	cal.RefreshInterval = time.Hour
+	for i := range events {
+		cal.Events = append(cal.Events, calendarEvent(c, &events[i]))
+	}
+	writeCalendar(c, cal, "events.ics")
+}
+
+// 单个活动导出为 .ics，仅限已发布的活动
+func exportEventICS(c *gin.Context, idParam string) {
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var event models.Event
+	if err := event.GetByID(c.Request.Context(), uint(id)); err != nil || event.PublishStatus != models.PublishStatusPublished {
+		utils.ErrorResponse(c, http.StatusNotFound, "event not found", nil)
+		return
+	}
+
+	cal := newEventCalendar(event.Title)
+	cal.Events = []utils.CalendarEvent{calendarEvent(c, &event)}
+	writeCalendar(c, cal, fmt.Sprintf("event-%d.ics", event.ID))
+}
+
+func newEventCalendar(name string) *utils.Calendar {
+	return &utils.Calendar{
+		ProdID:   calendarProdID,
+		Name:     name,
+		Location: calendarLocation(),
+	}
+}
+
+// 日历时区由 calendar.timezone 配置，无效时使用 UTC
+func calendarLocation() *time.Location {
+	name := viper.GetString("calendar.timezone")
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		logger.Log.Warnf("invalid calendar.timezone %q: %v", name, err)
+		return time.UTC
+	}
+	return loc
+}
+
+// UID 只由活动 ID 和站点域名决定，活动修改后客户端按 UID 更新而不是新增
+func calendarEvent(c *gin.Context, e *models.Event) utils.CalendarEvent {
+	site := siteURL(c)
+	domain := site
+	if u, err := url.Parse(site); err == nil && u.Host != "" {
+		domain = u.Hostname()
+	}
+
+	link := fmt.Sprintf("%s/events/%d", site, e.ID)
+	description := e.Description
+	if e.Link != "" {
+		description += "\n\n" + e.Link
+	}
+	if e.RegistrationLink != "" {
+		description += "\n\nRegistration: " + e.RegistrationLink
+	}
+
+	return utils.CalendarEvent{
+		UID:         fmt.Sprintf("event-%d@%s", e.ID, domain),
+		Sequence:    e.Sequence,
+		Summary:     e.Title,
+		Description: strings.TrimSpace(description),
+		Location:    e.Location,
+		URL:         link,
+		Categories:  e.Tags,
+		Start:       e.StartTime,
+		End:         e.EndTime,
+		Created:     e.CreatedAt,
+		Updated:     e.UpdatedAt,
+	}
+}
+
+// 输出日历并处理条件请求，ETag 取内容哈希，Last-Modified 取最近修改的活动
+func writeCalendar(c *gin.Context, cal *utils.Calendar, filename string) {
+	var lastModified time.Time
+	for _, e := range cal.Events {
+		if e.Updated.After(lastModified) {
+			lastModified = e.Updated
+		}
+	}
+
+	body := utils.RenderCalendar(cal)
+	etag := `"` + utils.HashContent(body)[:32] + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if utils.CheckNotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, calendarContentType, body)
+}
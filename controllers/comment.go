@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"hyperlane/models"
+	"hyperlane/policy"
+	"hyperlane/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 评论接口按目标类型（post / blog / event）复用，路由中的 :id 为目标 ID
+
+func QueryComments(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		order := c.DefaultQuery("order", "desc")
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+		filter := models.CommentFilter{
+			TargetType: targetType,
+			TargetId:   uint(targetId),
+			OrderDesc:  order == "desc",
+			Page:       page,
+			PageSize:   pageSize,
+		}
+
+		comments, total, err := models.QueryComments(c.Request.Context(), filter)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		var response = QueryCommentsResponse{
+			Comments: comments,
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "query success", response)
+	}
+}
+
+func CreateComment(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		var req CreateCommentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		exists, err := models.CommentTargetExists(c.Request.Context(), targetType, uint(targetId))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if !exists {
+			utils.ErrorResponse(c, http.StatusBadRequest, "target not found", nil)
+			return
+		}
+
+		uid, ok := c.Get("uid")
+		if !ok {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
+			return
+		}
+
+		userId, _ := uid.(uint)
+		var comment = models.Comment{
+			TargetType: targetType,
+			TargetId:   uint(targetId),
+			ParentId:   req.ParentId,
+			Content:    req.Content,
+			UserId:     userId,
+		}
+
+		if err := comment.Create(c.Request.Context()); err != nil {
+			c.Error(err)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "create success", comment)
+	}
+}
+
+func UpdateComment(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comment, ok := getTargetComment(c, targetType)
+		if !ok {
+			return
+		}
+
+		var req UpdateCommentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		if !authorize(c, policy.Comment, policy.Update, comment.UserId) {
+			return
+		}
+
+		comment.Content = req.Content
+		if err := comment.Update(c.Request.Context()); err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update comment", nil)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "success", comment)
+	}
+}
+
+func DeleteComment(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comment, ok := getTargetComment(c, targetType)
+		if !ok {
+			return
+		}
+
+		if !authorize(c, policy.Comment, policy.Delete, comment.UserId) {
+			return
+		}
+
+		if err := comment.Delete(c.Request.Context()); err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete comment", nil)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "delete success", nil)
+	}
+}
+
+// 根据路由参数取出评论，并校验评论属于该目标
+func getTargetComment(c *gin.Context, targetType string) (*models.Comment, bool) {
+	targetId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return nil, false
+	}
+
+	commentId, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid comment ID", nil)
+		return nil, false
+	}
+
+	var comment models.Comment
+	if err := comment.GetByID(c.Request.Context(), uint(commentId)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid comment", nil)
+		return nil, false
+	}
+
+	if comment.TargetType != targetType || comment.TargetId != uint(targetId) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid comment", nil)
+		return nil, false
+	}
+
+	return &comment, true
+}
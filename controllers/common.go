@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"hyperlane/models"
+	"hyperlane/policy"
+	"hyperlane/utils"
 )
 
 // event
@@ -14,18 +16,21 @@ type CreateEventRequest struct {
 	Link                 string   `json:"link"`
 	RegistrationLink     string   `json:"registration_link"`
 	RegistrationDeadline string   `json:"registration_deadline"`
+	Capacity             uint     `json:"capacity"`
 	StartTime            string   `json:"start_time" binding:"required"`
 	EndTime              string   `json:"end_time" binding:"required"`
 	CoverImg             string   `json:"cover_img" binding:"required"`
 	Tags                 []string `json:"tags"`
 	Twitter              string   `json:"twitter" binding:"required"`
+	Draft                bool     `json:"draft"` // 保存为草稿，稍后再提交审核
 }
 
 type QueryEventsResponse struct {
-	Events   []models.Event `json:"events"`
-	Page     int            `json:"page"`
-	PageSize int            `json:"page_size"`
-	Total    int64          `json:"total"`
+	Events     []models.Event `json:"events"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	Total      *int64         `json:"total,omitempty"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 type UpdateEventRequest struct {
@@ -42,10 +47,7 @@ type UpdateEventRequest struct {
 	Twitter              string   `json:"twitter" binding:"required"`
 	RegistrationLink     string   `json:"registration_link"`
 	RegistrationDeadline string   `json:"registration_deadline"`
-}
-
-type UpdateEventPublishStatusRequest struct {
-	PublishStatus uint `json:"publish_status"`
+	Capacity             *uint    `json:"capacity"` // 未传时保持原名额
 }
 
 // login
@@ -59,7 +61,17 @@ type LoginRequest struct {
 type LoginResponse struct {
 	models.User
 	Permissions []string `json:"permissions"`
-	Token       string   `json:"token"`
+	TokenResponse
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token 有效期（秒）
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // OAUTH
@@ -126,14 +138,16 @@ type CreateArticleRequest struct {
 	Tags       []string `json:"tags"`
 	Author     string   `json:"author" binding:"required"`
 	Translator string   `json:"translator"`
+	Draft      bool     `json:"draft"` // 保存为草稿，稍后再提交审核
 }
 
 type QueryArticlesResponse struct {
-	Blogs    []models.Article `json:"blogs"`
-	Guides   []models.Article `json:"guides"`
-	Page     int              `json:"page"`
-	PageSize int              `json:"page_size"`
-	Total    int64            `json:"total"`
+	Blogs      []models.Article `json:"blogs"`
+	Guides     []models.Article `json:"guides"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	Total      *int64           `json:"total,omitempty"`
+	NextCursor string           `json:"next_cursor,omitempty"`
 }
 
 type QueryBlogsResponse struct {
@@ -155,10 +169,6 @@ type UpdateArticleRequest struct {
 	Translator string   `json:"translator"`
 }
 
-type UpdateBlogPublishStatusRequest struct {
-	PublishStatus uint `json:"publish_status"`
-}
-
 // feedback
 type CreateFeedbackRequest struct {
 	Content string `json:"content" binding:"required"`
@@ -167,10 +177,11 @@ type CreateFeedbackRequest struct {
 }
 
 type QueryFeedbackResponse struct {
-	Feedbacks []models.Feedback `json:"feedbacks"`
-	Page      int               `json:"page"`
-	PageSize  int               `json:"page_size"`
-	Total     int64             `json:"total"`
+	Feedbacks  []models.Feedback `json:"feedbacks"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	Total      *int64            `json:"total,omitempty"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
 // Post
@@ -189,10 +200,11 @@ type UpdatePostRequest struct {
 }
 
 type QueryPostsResponse struct {
-	Posts    []models.Post `json:"posts"`
-	Page     int           `json:"page"`
-	PageSize int           `json:"page_size"`
-	Total    int64         `json:"total"`
+	Posts      []models.Post `json:"posts"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	Total      *int64        `json:"total,omitempty"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
 // recap
@@ -214,10 +226,199 @@ type UpdateRecapRequest struct {
 type UpdateUserRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Username string `json:"username" binding:"required"`
-	Avatar   string `json:"avatar" binding:"required"`
+	Avatar   string `json:"avatar"` // 为空时保留原头像
 	Github   string `json:"github"`
 }
 
 type FollowStatesRequest struct {
 	UserIDs []uint `json:"user_ids" binding:"required"`
 }
+
+// dapp
+type CreateDappRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Desc      string   `json:"desc" binding:"required"`
+	Chain     string   `json:"chain" binding:"required"`
+	Category  string   `json:"category" binding:"required"`
+	Website   string   `json:"website" binding:"required"`
+	Contracts []string `json:"contracts"`
+	Logo      string   `json:"logo" binding:"required"`
+	Tags      []string `json:"tags"`
+	Twitter   string   `json:"twitter"`
+}
+
+type UpdateDappRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Desc      string   `json:"desc" binding:"required"`
+	Chain     string   `json:"chain" binding:"required"`
+	Category  string   `json:"category" binding:"required"`
+	Website   string   `json:"website" binding:"required"`
+	Contracts []string `json:"contracts"`
+	Logo      string   `json:"logo" binding:"required"`
+	Tags      []string `json:"tags"`
+	Twitter   string   `json:"twitter"`
+}
+
+type QueryDappsResponse struct {
+	Dapps    []models.Dapp `json:"dapps"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	Total    int64         `json:"total"`
+}
+
+type UpdateDappPublishStatusRequest struct {
+	PublishStatus uint `json:"publish_status"`
+}
+
+// comment
+type CreateCommentRequest struct {
+	Content  string `json:"content" binding:"required"`
+	ParentId *uint  `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+type QueryCommentsResponse struct {
+	Comments []models.Comment `json:"comments"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+	Total    int64            `json:"total"`
+}
+
+// event registration
+type RegisterEventRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type QueryRegistrationsResponse struct {
+	Registrations []models.EventRegistration `json:"registrations"`
+	Page          int                        `json:"page"`
+	PageSize      int                        `json:"page_size"`
+	Total         int64                      `json:"total"`
+}
+
+// job
+type QueryJobRunsResponse struct {
+	Runs     []models.JobRun `json:"runs"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Total    int64           `json:"total"`
+}
+
+// notification
+type QueryNotificationsResponse struct {
+	Notifications []models.Notification `json:"notifications"`
+	Page          int                   `json:"page"`
+	PageSize      int                   `json:"page_size"`
+	Total         int64                 `json:"total"`
+}
+
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// search
+type SearchResponse struct {
+	*models.SearchResult
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// rbac admin
+type RoleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type PermissionGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type AssignRoleRequest struct {
+	RoleId uint `json:"role_id" binding:"required"`
+}
+
+type QueryAuditLogsResponse struct {
+	Logs     []models.AuditLog `json:"logs"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Total    int64             `json:"total"`
+}
+
+// upload
+
+type QueryUploadsResponse struct {
+	Uploads  []models.Upload `json:"uploads"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Total    int64           `json:"total"`
+}
+
+// review
+
+type ReviewRequest struct {
+	Action string `json:"action" binding:"required"` // submit / withdraw / request_changes / approve / publish / unpublish / archive
+	Note   string `json:"note"`                      // 审核意见，退回修改时必填
+}
+
+// article revision
+
+type QueryRevisionsResponse struct {
+	Revisions []models.ArticleRevision `json:"revisions"`
+	Page      int                      `json:"page"`
+	PageSize  int                      `json:"page_size"`
+	Total     int64                    `json:"total"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type RevisionDiffResponse struct {
+	FromVersion uint             `json:"from_version"` // 0 表示与空内容比较
+	ToVersion   uint             `json:"to_version"`
+	Changes     []FieldChange    `json:"changes"` // 正文以外变化的字段
+	Lines       []utils.DiffLine `json:"lines"`   // 正文逐行差异
+}
+
+type ReviewRevisionRequest struct {
+	Note string `json:"note"` // 审核意见，退回时必填
+}
+
+type MyPermissionsResponse struct {
+	Permissions  []string                           `json:"permissions"`
+	Capabilities map[string]map[string]policy.Scope `json:"capabilities"` // 资源 -> 操作 -> none / own / any
+}
+
+type QueryModerationQueueResponse struct {
+	Items    []models.ModerationItem `json:"items"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	Total    int64                   `json:"total"`
+}
+
+type ModerationTarget struct {
+	TargetType string `json:"target_type" binding:"required,oneof=blog event"`
+	TargetId   uint   `json:"target_id" binding:"required"`
+}
+
+type BulkModerationRequest struct {
+	Action string             `json:"action" binding:"required,oneof=approve request_changes"`
+	Note   string             `json:"note"` // 退回修改时必填，所有条目共用
+	Items  []ModerationTarget `json:"items" binding:"required,min=1,max=50,dive"`
+}
+
+// 批量审核中单条内容的结果，失败时给出错误码
+type BulkModerationResult struct {
+	TargetType string `json:"target_type"`
+	TargetId   uint   `json:"target_id"`
+	Success    bool   `json:"success"`
+	ErrorCode  string `json:"error_code,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
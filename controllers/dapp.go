@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"hyperlane/models"
+	"hyperlane/policy"
+	"hyperlane/utils"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateDapp(c *gin.Context) {
+	var req CreateDappRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var dapp = models.Dapp{
+		Name:        req.Name,
+		Description: req.Desc,
+		Chain:       req.Chain,
+		Category:    req.Category,
+		Website:     req.Website,
+		Contracts:   req.Contracts,
+		Logo:        req.Logo,
+		Tags:        req.Tags,
+		Twitter:     req.Twitter,
+	}
+
+	uid, ok := c.Get("uid")
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	userId, _ := uid.(uint)
+	dapp.PublisherId = userId
+	// 创建数据库记录
+	if err := dapp.Create(c.Request.Context()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "create success", dapp)
+}
+
+func GetDapp(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var dapp models.Dapp
+	dapp.ID = uint(id)
+
+	if err = dapp.GetByID(c.Request.Context(), uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Dapp", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", dapp)
+}
+
+func QueryDapps(c *gin.Context) {
+	keyword := c.Query("keyword")
+	chain := c.Query("chain")
+	category := c.Query("category")
+	tag := c.Query("tag")
+	order := c.DefaultQuery("order", "desc")
+	publishStatus, _ := strconv.Atoi(c.DefaultQuery("publish_status", "0"))
+	userId, _ := strconv.Atoi(c.Query("user_id"))
+
+	// 未审核、待审核等状态的 Dapp 只对审核人员可见，其他调用方只能查询已发布的
+	if !slices.Contains(c.GetStringSlice("permissions"), "dapp:review") {
+		if publishStatus != 0 && publishStatus != int(models.PublishStatusPublished) {
+			c.Error(utils.ErrForbidden)
+			return
+		}
+		publishStatus = int(models.PublishStatusPublished)
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "6"))
+
+	filter := models.DappFilter{
+		Keyword:       keyword,
+		Chain:         chain,
+		Category:      category,
+		Tag:           tag,
+		PublishStatus: publishStatus,
+		PublisherId:   userId,
+		OrderDesc:     order == "desc",
+		Page:          page,
+		PageSize:      pageSize,
+	}
+
+	dapps, total, err := models.QueryDapps(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var response = QueryDappsResponse{
+		Dapps:    dapps,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
+
+func DeleteDapp(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+	var dapp models.Dapp
+	dapp.ID = uint(id)
+
+	if err = dapp.GetByID(c.Request.Context(), uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Dapp", nil)
+		return
+	}
+
+	if !authorize(c, policy.Dapp, policy.Delete, dapp.PublisherId) {
+		return
+	}
+
+	if err := dapp.Delete(c.Request.Context()); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete dapp", nil)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "delete success", nil)
+}
+
+func UpdateDapp(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var req UpdateDappRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var dapp models.Dapp
+	dapp.ID = uint(id)
+
+	if err = dapp.GetByID(c.Request.Context(), uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Dapp", nil)
+		return
+	}
+
+	if !authorize(c, policy.Dapp, policy.Update, dapp.PublisherId) {
+		return
+	}
+
+	dapp.Name = req.Name
+	dapp.Description = req.Desc
+	dapp.Chain = req.Chain
+	dapp.Category = req.Category
+	dapp.Website = req.Website
+	dapp.Contracts = req.Contracts
+	dapp.Logo = req.Logo
+	dapp.Tags = req.Tags
+	dapp.Twitter = req.Twitter
+
+	dapp.PublishStatus = 1 // 更新后需要重新审核
+
+	if err := dapp.Update(c.Request.Context()); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update dapp", nil)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "success", dapp)
+}
+
+func UpdateDappPublishStatus(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var req UpdateDappPublishStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if req.PublishStatus != 1 && req.PublishStatus != 2 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	var dapp models.Dapp
+	dapp.ID = uint(id)
+
+	if err = dapp.GetByID(c.Request.Context(), uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Dapp", nil)
+		return
+	}
+
+	now := time.Now()
+	dapp.PublishStatus = req.PublishStatus
+	dapp.PublishTime = &now
+
+	if err := dapp.Update(c.Request.Context()); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update dapp", nil)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "success", dapp)
+}
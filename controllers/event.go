@@ -1,11 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"hyperlane/models"
+	"hyperlane/policy"
 	"hyperlane/utils"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -36,6 +39,7 @@ func CreateEvent(c *gin.Context) {
 		Location:         req.Location,
 		Link:             req.Link,
 		RegistrationLink: req.RegistrationLink,
+		Capacity:         req.Capacity,
 		StartTime:        startT,
 		EndTime:          endT,
 		CoverImg:         req.CoverImg,
@@ -66,9 +70,12 @@ func CreateEvent(c *gin.Context) {
 	}
 
 	event.UserId = userId
+	if req.Draft {
+		event.PublishStatus = models.PublishStatusDraft
+	}
 	// 创建数据库记录
-	if err := event.Create(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := event.Create(c.Request.Context()); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -77,6 +84,12 @@ func CreateEvent(c *gin.Context) {
 
 func GetEvent(c *gin.Context) {
 	idParam := c.Param("id")
+	// /events/:id.ics 与 /events/:id 共用路由参数
+	if strings.HasSuffix(idParam, ".ics") {
+		exportEventICS(c, strings.TrimSuffix(idParam, ".ics"))
+		return
+	}
+
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
@@ -86,11 +99,15 @@ func GetEvent(c *gin.Context) {
 	var event models.Event
 	event.ID = uint(id)
 
-	if err = event.GetByID(uint(id)); err != nil {
+	if err = event.GetByID(c.Request.Context(), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Event", nil)
 		return
 	}
 
+	if event.PublishStatus == models.PublishStatusPublished && recordView(c, models.CommentTargetEvent, event.ID) {
+		event.ViewCount++
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "success", event)
 }
 
@@ -111,6 +128,12 @@ func QueryEvents(c *gin.Context) {
 
 	publishStatus, _ := strconv.Atoi(c.DefaultQuery("publish_status", "0"))
 
+	cursor, err := listCursor(c, models.CursorScopeEvents, order == "desc")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	filter := models.EventFilter{
 		Keyword:       keyword,
 		Tag:           tag,
@@ -122,6 +145,8 @@ func QueryEvents(c *gin.Context) {
 		PageSize:      pageSize,
 		Status:        status,
 		PublishStatus: publishStatus,
+		Cursor:        cursor,
+		SkipTotal:     skipTotal(c, cursor),
 	}
 
 	var start, end time.Time
@@ -134,9 +159,9 @@ func QueryEvents(c *gin.Context) {
 		filter.EndDate = &newEnd
 	}
 
-	events, total, err := models.QueryEvents(filter)
+	events, total, err := models.QueryEvents(c.Request.Context(), filter)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
@@ -144,7 +169,10 @@ func QueryEvents(c *gin.Context) {
 		Events:   events,
 		Page:     page,
 		PageSize: pageSize,
-		Total:    total,
+		Total:    totalOrNil(total),
+	}
+	if cursor != nil {
+		response.NextCursor = models.NextCursor(cursor, events, pageSize)
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "query success", response)
@@ -160,12 +188,16 @@ func DeleteEvent(c *gin.Context) {
 	var event models.Event
 	event.ID = uint(id)
 
-	if err = event.GetByID(uint(id)); err != nil {
+	if err = event.GetByID(c.Request.Context(), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Event", nil)
 		return
 	}
 
-	if err := event.Delete(); err != nil {
+	if !authorize(c, policy.Event, policy.Delete, event.UserId) {
+		return
+	}
+
+	if err := event.Delete(c.Request.Context()); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete event", nil)
 		return
 	}
@@ -182,18 +214,22 @@ func UpdateEvent(c *gin.Context) {
 
 	var req UpdateEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", nil)
+		c.Error(err)
 		return
 	}
 
 	var event models.Event
 	event.ID = uint(id)
 
-	if err = event.GetByID(uint(id)); err != nil {
+	if err = event.GetByID(c.Request.Context(), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Event", nil)
 		return
 	}
 
+	if !authorize(c, policy.Event, policy.Update, event.UserId) {
+		return
+	}
+
 	startT, _ := utils.ParseTime(req.StartTime)
 	endT, _ := utils.ParseTime(req.EndTime)
 
@@ -209,6 +245,9 @@ func UpdateEvent(c *gin.Context) {
 	event.Tags = req.Tags
 	event.Twitter = req.Twitter
 	event.RegistrationLink = req.RegistrationLink
+	if req.Capacity != nil {
+		event.Capacity = *req.Capacity
+	}
 	if req.RegistrationDeadline != "" {
 		regisDeadline, err := utils.ParseTime(req.RegistrationDeadline)
 		if err != nil {
@@ -218,46 +257,11 @@ func UpdateEvent(c *gin.Context) {
 		event.RegistrationDeadline = &regisDeadline
 	}
 
-	if err := event.Update(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update event", nil)
-		return
-	}
-	utils.SuccessResponse(c, http.StatusOK, "success", event)
-}
-
-func UpdateEventPublishStatus(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
-		return
-	}
-
-	var req UpdateEventPublishStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", nil)
-		return
-	}
-
-	var event models.Event
-	event.ID = uint(id)
-
-	if err = event.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Event", nil)
-		return
-	}
-
-	if event.PublishStatus != 1 && event.PublishStatus != 2 {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid status", nil)
-		return
-	}
-
-	// TODO: 2 -> 1 ?
-	now := time.Now()
-	event.PublishStatus = req.PublishStatus
-	event.PublishTime = &now
-
-	if err := event.Update(); err != nil {
+	if err := event.Update(c.Request.Context()); err != nil {
+		if errors.Is(err, models.ErrCapacityBelowParticipants) {
+			c.Error(err)
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update event", nil)
 		return
 	}
@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"fmt"
+	"hyperlane/logger"
+	"hyperlane/middlewares"
+	"hyperlane/models"
+	"hyperlane/utils"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// 订阅源最多输出的条目数
+const feedLimit = 20
+
+// BlogFeed 已发布博客的订阅源，支持 category、tag 过滤
+func BlogFeed(c *gin.Context) {
+	format, ok := feedFormat(c)
+	if !ok {
+		return
+	}
+
+	articles, _, err := models.QueryArticles(c.Request.Context(), models.ArticleFilter{
+		Tag:           c.Query("tag"),
+		Category:      c.Query("category"),
+		OrderDesc:     true,
+		PublishStatus: int(models.PublishStatusPublished),
+		Page:          1,
+		PageSize:      feedLimit,
+		SkipTotal:     true,
+	})
+	if err != nil {
+		logger.Log.Errorf("query blog feed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to load feed", nil)
+		return
+	}
+
+	site := siteURL(c)
+	feed := &utils.Feed{
+		Title:       "Hyperlane Blogs",
+		Description: "Latest blogs on Hyperlane",
+		Link:        site + "/blogs",
+		FeedURL:     requestURL(c),
+	}
+	for i := range articles {
+		feed.Items = append(feed.Items, articleFeedItem(site, &articles[i]))
+	}
+	writeFeed(c, feed, format)
+}
+
+// EventFeed 即将开始的已发布活动，按开始时间排序
+func EventFeed(c *gin.Context) {
+	format, ok := feedFormat(c)
+	if !ok {
+		return
+	}
+
+	events, _, err := models.QueryEvents(c.Request.Context(), models.EventFilter{
+		Tag:           c.Query("tag"),
+		Status:        0,
+		PublishStatus: int(models.PublishStatusPublished),
+		Page:          1,
+		PageSize:      feedLimit,
+		SkipTotal:     true,
+	})
+	if err != nil {
+		logger.Log.Errorf("query event feed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to load feed", nil)
+		return
+	}
+
+	site := siteURL(c)
+	feed := &utils.Feed{
+		Title:       "Hyperlane Events",
+		Description: "Upcoming events on Hyperlane",
+		Link:        site + "/events",
+		FeedURL:     requestURL(c),
+	}
+	for _, e := range events {
+		link := fmt.Sprintf("%s/events/%d", site, e.ID)
+		content := fmt.Sprintf("%s\n\nTime: %s - %s", e.Description,
+			e.StartTime.UTC().Format(time.RFC3339), e.EndTime.UTC().Format(time.RFC3339))
+		if e.Location != "" {
+			content += "\nLocation: " + e.Location
+		}
+		feed.Items = append(feed.Items, utils.FeedItem{
+			ID:        link,
+			Title:     e.Title,
+			Link:      link,
+			Summary:   e.Description,
+			Content:   content,
+			Tags:      e.Tags,
+			Published: publishedAt(e.PublishTime, e.CreatedAt),
+			Updated:   e.UpdatedAt,
+		})
+	}
+	writeFeed(c, feed, format)
+}
+
+// UserActivityFeed 用户的动态与已发布博客，按时间倒序合并
+func UserActivityFeed(c *gin.Context) {
+	format, ok := feedFormat(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+	user, err := models.GetUserById(c.Request.Context(), uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "user not found", nil)
+		return
+	}
+
+	posts, _, err := models.QueryPosts(c.Request.Context(), models.PostFilter{
+		UserId:    user.ID,
+		OrderDesc: true,
+		Page:      1,
+		PageSize:  feedLimit,
+		SkipTotal: true,
+	})
+	if err != nil {
+		logger.Log.Errorf("query user feed posts: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to load feed", nil)
+		return
+	}
+	articles, _, err := models.QueryArticles(c.Request.Context(), models.ArticleFilter{
+		PublisherId:   int(user.ID),
+		OrderDesc:     true,
+		PublishStatus: int(models.PublishStatusPublished),
+		Page:          1,
+		PageSize:      feedLimit,
+		SkipTotal:     true,
+	})
+	if err != nil {
+		logger.Log.Errorf("query user feed articles: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to load feed", nil)
+		return
+	}
+
+	site := siteURL(c)
+	feed := &utils.Feed{
+		Title:       user.Username + " on Hyperlane",
+		Description: "Posts and blogs by " + user.Username,
+		Link:        fmt.Sprintf("%s/users/%d", site, user.ID),
+		FeedURL:     requestURL(c),
+	}
+	for _, p := range posts {
+		link := fmt.Sprintf("%s/posts/%d", site, p.ID)
+		feed.Items = append(feed.Items, utils.FeedItem{
+			ID:        link,
+			Title:     p.Title,
+			Link:      link,
+			Summary:   p.Description,
+			Author:    user.Username,
+			Tags:      p.Tags,
+			Published: p.CreatedAt,
+			Updated:   p.UpdatedAt,
+		})
+	}
+	for i := range articles {
+		feed.Items = append(feed.Items, articleFeedItem(site, &articles[i]))
+	}
+
+	sort.SliceStable(feed.Items, func(i, j int) bool {
+		return feed.Items[i].Published.After(feed.Items[j].Published)
+	})
+	if len(feed.Items) > feedLimit {
+		feed.Items = feed.Items[:feedLimit]
+	}
+	writeFeed(c, feed, format)
+}
+
+func articleFeedItem(site string, a *models.Article) utils.FeedItem {
+	link := fmt.Sprintf("%s/blogs/%d", site, a.ID)
+	author := a.Author
+	if author == "" && a.Publisher != nil {
+		author = a.Publisher.Username
+	}
+	return utils.FeedItem{
+		ID:        link,
+		Title:     a.Title,
+		Link:      link,
+		Summary:   a.Description,
+		Content:   a.Content,
+		Author:    author,
+		Tags:      a.Tags,
+		Published: publishedAt(a.PublishTime, a.CreatedAt),
+		Updated:   a.UpdatedAt,
+	}
+}
+
+func publishedAt(publishTime *time.Time, createdAt time.Time) time.Time {
+	if publishTime != nil {
+		return *publishTime
+	}
+	return createdAt
+}
+
+func feedFormat(c *gin.Context) (string, bool) {
+	format := c.Param("format")
+	if _, ok := utils.FeedContentTypes[format]; !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "unsupported feed format, use rss, atom or json", nil)
+		return "", false
+	}
+	return format, true
+}
+
+// 条目链接指向前端站点，未配置 site.url 时使用请求地址
+func siteURL(c *gin.Context) string {
+	if site := viper.GetString("site.url"); site != "" {
+		return strings.TrimSuffix(site, "/")
+	}
+	return requestScheme(c) + "://" + c.Request.Host
+}
+
+func requestURL(c *gin.Context) string {
+	return requestScheme(c) + "://" + c.Request.Host + c.Request.URL.RequestURI()
+}
+
+// 订阅源以 Cache-Control: public 输出，客户端伪造的 X-Forwarded-Proto 会污染共享缓存，
+// 因此只采用可信代理写入的值
+func requestScheme(c *gin.Context) string {
+	if proto := c.GetHeader("X-Forwarded-Proto"); (proto == "http" || proto == "https") && middlewares.FromTrustedProxy(c) {
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// 输出订阅源并处理条件请求，ETag 取内容哈希，Last-Modified 取最近更新的条目
+func writeFeed(c *gin.Context, feed *utils.Feed, format string) {
+	for _, item := range feed.Items {
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		if item.Published.After(feed.Updated) {
+			feed.Updated = item.Published
+		}
+	}
+
+	body, err := utils.RenderFeed(feed, format)
+	if err != nil {
+		logger.Log.Errorf("render %s feed: %v", format, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to render feed", nil)
+		return
+	}
+
+	etag := `"` + utils.HashContent(body)[:32] + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !feed.Updated.IsZero() {
+		c.Header("Last-Modified", feed.Updated.UTC().Format(http.TimeFormat))
+	}
+	if utils.CheckNotModified(c.Request, etag, feed.Updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, utils.FeedContentTypes[format], body)
+}
@@ -13,7 +13,7 @@ func CreateFeedback(c *gin.Context) {
 	var req CreateFeedbackRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
@@ -33,8 +33,8 @@ func CreateFeedback(c *gin.Context) {
 	feedback.UserId = &userId
 
 	// 创建数据库记录
-	if err := feedback.Create(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := feedback.Create(c.Request.Context()); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -46,15 +46,23 @@ func QueryFeedbacks(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "6"))
 
+	cursor, err := listCursor(c, models.CursorScopeFeedback, order == "desc")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	filter := models.FeedbackFilter{
 		OrderDesc: order == "desc",
 		Page:      page,
 		PageSize:  pageSize,
+		Cursor:    cursor,
+		SkipTotal: skipTotal(c, cursor),
 	}
 
-	feedbacks, total, err := models.QueryFeedback(filter)
+	feedbacks, total, err := models.QueryFeedback(c.Request.Context(), filter)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
@@ -62,7 +70,10 @@ func QueryFeedbacks(c *gin.Context) {
 		Feedbacks: feedbacks,
 		Page:      page,
 		PageSize:  pageSize,
-		Total:     total,
+		Total:     totalOrNil(total),
+	}
+	if cursor != nil {
+		response.NextCursor = models.NextCursor(cursor, feedbacks, pageSize)
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "query success", response)
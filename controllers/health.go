@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"context"
+	"hyperlane/logger"
+	"hyperlane/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz 存活探针，进程能处理请求即返回 200
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz 就绪探针，数据库不可用时返回 503，编排系统据此摘除流量
+func Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	if err := models.Ping(ctx); err != nil {
+		logger.WithContext(ctx).Warnf("readiness check failed: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": "unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "database": "ok"})
+}
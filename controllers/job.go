@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"hyperlane/models"
+	"hyperlane/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func QueryJobRuns(c *gin.Context) {
+	name := c.Query("name")
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	filter := models.JobRunFilter{
+		Name:     name,
+		Status:   status,
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	runs, total, err := models.QueryJobRuns(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var response = QueryJobRunsResponse{
+		Runs:     runs,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
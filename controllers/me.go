@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"hyperlane/policy"
+	"hyperlane/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 当前登录用户，权限来自 JWT 中间件
+func currentSubject(c *gin.Context) policy.Subject {
+	return policy.Subject{
+		UserId:      c.GetUint("uid"),
+		Permissions: c.GetStringSlice("permissions"),
+	}
+}
+
+// authorize 按 policy 校验当前用户能否操作所有者为 ownerId 的资源，拒绝时已写入错误
+func authorize(c *gin.Context, resource, action string, ownerId uint) bool {
+	if err := policy.Authorize(currentSubject(c), resource, action, ownerId); err != nil {
+		c.Error(err)
+		return false
+	}
+	return true
+}
+
+// MyPermissions 当前用户的权限和对各类资源的操作范围
+func MyPermissions(c *gin.Context) {
+	subject := currentSubject(c)
+	permissions := subject.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	utils.SuccessResponse(c, http.StatusOK, "query success", MyPermissionsResponse{
+		Permissions:  permissions,
+		Capabilities: policy.Capabilities(subject),
+	})
+}
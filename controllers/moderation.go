@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/utils"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 参与审核队列的内容类型
+var moderationTypes = []string{models.CommentTargetBlog, models.CommentTargetEvent, models.ModerationTypeRevision}
+
+// 处理该类型所需的审核权限，博客修订由博客审核人员处理
+func moderationPermission(typ string) string {
+	if typ == models.ModerationTypeRevision {
+		return "blog:review"
+	}
+	return typ + ":review"
+}
+
+// 当前用户有审核权限的内容类型，type 参数可进一步限定为其中一种；没有任何审核权限时已写入错误
+func reviewableTypes(c *gin.Context) ([]string, bool) {
+	permissions := c.GetStringSlice("permissions")
+	only := c.Query("type")
+
+	var types []string
+	for _, typ := range moderationTypes {
+		if slices.Contains(permissions, moderationPermission(typ)) && (only == "" || only == typ) {
+			types = append(types, typ)
+		}
+	}
+	if len(types) == 0 {
+		c.Error(utils.ErrForbidden)
+		return nil, false
+	}
+	return types, true
+}
+
+func QueryModerationQueue(c *gin.Context) {
+	types, ok := reviewableTypes(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	items, total, err := models.QueryModerationQueue(c.Request.Context(), models.ModerationFilter{
+		Types:      types,
+		ReviewerId: c.GetUint("uid"),
+		Claim:      c.Query("claim"),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", QueryModerationQueueResponse{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// 解析 :target_type/:id 并校验审核权限
+func moderationTarget(c *gin.Context) (string, uint, bool) {
+	targetType := c.Param("target_type")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || !slices.Contains(moderationTypes, targetType) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid target", nil)
+		return "", 0, false
+	}
+	if !slices.Contains(c.GetStringSlice("permissions"), moderationPermission(targetType)) {
+		c.Error(utils.ErrForbidden)
+		return "", 0, false
+	}
+	return targetType, uint(id), true
+}
+
+func ClaimModerationItem(c *gin.Context) {
+	targetType, id, ok := moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	claim, err := models.ClaimContent(c.Request.Context(), targetType, id, c.GetUint("uid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "claim success", claim)
+}
+
+func UnclaimModerationItem(c *gin.Context) {
+	targetType, id, ok := moderationTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := models.UnclaimContent(c.Request.Context(), targetType, id, c.GetUint("uid")); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "unclaim success", nil)
+}
+
+// BulkModerate 逐条执行审核动作，单条失败不影响其他条目，结果按请求顺序返回
+func BulkModerate(c *gin.Context) {
+	var req BulkModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.Action == models.ReviewActionRequestChanges && req.Note == "" {
+		c.Error(models.ErrReviewNoteRequired)
+		return
+	}
+
+	results := make([]BulkModerationResult, len(req.Items))
+	for i, item := range req.Items {
+		results[i] = BulkModerationResult{TargetType: item.TargetType, TargetId: item.TargetId}
+		actor := reviewActor(c, item.TargetType)
+		_, err := models.TransitionContent(c.Request.Context(), item.TargetType, item.TargetId, actor, req.Action, req.Note)
+		if err == nil {
+			results[i].Success = true
+			continue
+		}
+
+		appErr := utils.ToAppError(err)
+		if appErr.Status >= http.StatusInternalServerError {
+			logger.Log.Errorf("bulk moderate %s %d: %v", item.TargetType, item.TargetId, err)
+		}
+		results[i].ErrorCode = appErr.Code
+		results[i].Message = utils.Localize(c.GetHeader("Accept-Language"), appErr.Code, appErr.Message)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", results)
+}
+
+// ModerationStats 审核积压和审核时效，days 为统计窗口（默认 30 天）
+func ModerationStats(c *gin.Context) {
+	types, ok := reviewableTypes(c)
+	if !ok {
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 || days > 365 {
+		days = 30
+	}
+
+	report, err := models.GetModerationStats(c.Request.Context(), types, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "query success", report)
+}
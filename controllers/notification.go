@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func QueryNotifications(c *gin.Context) {
+	unread := c.Query("unread") == "true"
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter := models.NotificationFilter{
+		UserId:     c.GetUint("uid"),
+		UnreadOnly: unread,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+
+	notifications, total, err := models.QueryNotifications(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.Errorf("query notifications failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	var response = QueryNotificationsResponse{
+		Notifications: notifications,
+		Page:          page,
+		PageSize:      pageSize,
+		Total:         total,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
+
+func GetUnreadNotificationCount(c *gin.Context) {
+	count, err := models.CountUnreadNotifications(c.Request.Context(), c.GetUint("uid"))
+	if err != nil {
+		logger.Log.Errorf("count unread notifications failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", UnreadCountResponse{Unread: count})
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	if err := models.MarkNotificationRead(c.Request.Context(), c.GetUint("uid"), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", nil)
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	if _, err := models.MarkAllNotificationsRead(c.Request.Context(), c.GetUint("uid")); err != nil {
+		logger.Log.Errorf("mark all notifications read failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", nil)
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"hyperlane/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listCursor 读取 cursor 参数：未携带时返回 nil，使用页码分页；
+// 携带空值（?cursor=）表示游标模式的第一页，排序方向取 order 参数，之后的页沿用游标中的方向
+func listCursor(c *gin.Context, scope string, desc bool) (*utils.Cursor, error) {
+	raw, ok := c.GetQuery("cursor")
+	if !ok {
+		return nil, nil
+	}
+	if raw == "" {
+		return &utils.Cursor{Scope: scope, Desc: desc}, nil
+	}
+	return utils.DecodeCursor(raw, scope)
+}
+
+// skipTotal 游标模式默认不统计总数，with_total=true 时统计；页码模式保持原有行为，with_total=false 时跳过
+func skipTotal(c *gin.Context, cursor *utils.Cursor) bool {
+	if cursor != nil {
+		return c.Query("with_total") != "true"
+	}
+	return c.Query("with_total") == "false"
+}
+
+// 未统计总数（-1）时响应中不输出 total
+func totalOrNil(total int64) *int64 {
+	if total < 0 {
+		return nil
+	}
+	return &total
+}
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"hyperlane/metrics"
 	"hyperlane/models"
+	"hyperlane/policy"
 	"hyperlane/utils"
 	"net/http"
 	"strconv"
@@ -15,7 +17,7 @@ func CreatePost(c *gin.Context) {
 	var req CreatePostRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
@@ -35,11 +37,12 @@ func CreatePost(c *gin.Context) {
 	userId, _ := uid.(uint)
 	post.UserId = userId
 
-	if err := post.Create(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := post.Create(c.Request.Context()); err != nil {
+		c.Error(err)
 		return
 	}
 
+	metrics.PostsCreated.Inc()
 	utils.SuccessResponse(c, http.StatusOK, "create success", post)
 }
 
@@ -54,11 +57,16 @@ func GetPost(c *gin.Context) {
 	var post models.Post
 	post.ID = uint(id)
 
-	if err = post.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid post", nil)
+	if err = post.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
+	// 计入的浏览量异步写入，响应中先加上本次浏览
+	if recordView(c, models.CommentTargetPost, post.ID) {
+		post.ViewCount++
+	}
+
 	utils.SuccessResponse(c, http.StatusOK, "success", post)
 }
 
@@ -72,24 +80,16 @@ func DeletePost(c *gin.Context) {
 	var post models.Post
 	post.ID = uint(id)
 
-	if err = post.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid post", nil)
-		return
-	}
-
-	uid, ok := c.Get("uid")
-	if !ok {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
+	if err = post.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
-	userId, _ := uid.(uint)
-	if post.UserId != userId {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "not author", nil)
+	if !authorize(c, policy.Post, policy.Delete, post.UserId) {
 		return
 	}
 
-	if err := post.Delete(); err != nil {
+	if err := post.Delete(c.Request.Context()); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete post", nil)
 		return
 	}
@@ -106,27 +106,19 @@ func UpdatePost(c *gin.Context) {
 
 	var req UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", nil)
+		c.Error(err)
 		return
 	}
 
 	var post models.Post
 	post.ID = uint(id)
 
-	if err = post.GetByID(uint(id)); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid post", nil)
-		return
-	}
-
-	uid, ok := c.Get("uid")
-	if !ok {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
+	if err = post.GetByID(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
-	userId, _ := uid.(uint)
-	if post.UserId != userId {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "not author", nil)
+	if !authorize(c, policy.Post, policy.Update, post.UserId) {
 		return
 	}
 
@@ -135,7 +127,7 @@ func UpdatePost(c *gin.Context) {
 	post.Tags = req.Tags
 	post.Twitter = req.Twitter
 
-	if err := post.Update(); err != nil {
+	if err := post.Update(c.Request.Context()); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update post", nil)
 		return
 	}
@@ -153,19 +145,25 @@ func QueryPosts(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 
+	if mode := c.Query("feed"); mode != "" {
+		queryPostFeed(c, mode, pageSize)
+		return
+	}
+
+	cursor, err := listCursor(c, models.CursorScopePosts, order == "desc")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	filter := models.PostFilter{
 		Keyword:   keyword,
 		UserId:    uint(userId),
 		OrderDesc: order == "desc",
 		Page:      page,
 		PageSize:  pageSize,
-	}
-
-	uid, ok := c.Get("uid")
-	if ok {
-		userId, _ := uid.(uint)
-		filter.FollowingOf = userId
-		filter.Hybrid = true
+		Cursor:    cursor,
+		SkipTotal: skipTotal(c, cursor),
 	}
 
 	var start, end time.Time
@@ -178,9 +176,9 @@ func QueryPosts(c *gin.Context) {
 		filter.EndDate = &newEnd
 	}
 
-	posts, total, err := models.QueryPosts(filter)
+	posts, total, err := models.QueryPosts(c.Request.Context(), filter)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
@@ -188,16 +186,49 @@ func QueryPosts(c *gin.Context) {
 		Posts:    posts,
 		Page:     page,
 		PageSize: pageSize,
-		Total:    total,
+		Total:    totalOrNil(total),
+	}
+	if cursor != nil {
+		response.NextCursor = models.NextCursor(cursor, posts, pageSize)
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "query success", response)
 }
 
+// 信息流按热度排序，只支持游标翻页；关注流和混合流需要登录
+func queryPostFeed(c *gin.Context, mode string, pageSize int) {
+	if mode != models.FeedFollowing && mode != models.FeedDiscover && mode != models.FeedHybrid {
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid feed", nil)
+		return
+	}
+	userId := c.GetUint("uid")
+	if userId == 0 && mode != models.FeedDiscover {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Please log in to continue!", nil)
+		return
+	}
+
+	posts, next, err := models.QueryFeed(c.Request.Context(), models.FeedQuery{
+		Mode:     mode,
+		UserId:   userId,
+		PageSize: pageSize,
+		Cursor:   c.Query("cursor"),
+	}, models.LoadFeedRanking())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", QueryPostsResponse{
+		Posts:      posts,
+		PageSize:   pageSize,
+		NextCursor: next,
+	})
+}
+
 func PostsStats(c *gin.Context) {
-	stats, err := models.GetPostStats(6)
+	stats, err := models.GetPostStats(c.Request.Context(), 6)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 	utils.SuccessResponse(c, http.StatusOK, "query success", stats)
@@ -213,11 +244,12 @@ func LikePost(c *gin.Context) {
 	}
 
 	userId := c.GetUint("uid")
-	if err := models.LikePost(uint(postId), userId); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := models.LikePost(c.Request.Context(), uint(postId), userId); err != nil {
+		c.Error(err)
 		return
 	}
 
+	metrics.Likes.Inc()
 	utils.SuccessResponse(c, http.StatusOK, "like success", nil)
 }
 
@@ -231,8 +263,8 @@ func UnlikePost(c *gin.Context) {
 	}
 
 	userId := c.GetUint("uid")
-	if err := models.UnlikePost(uint(postId), userId); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := models.UnlikePost(c.Request.Context(), uint(postId), userId); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -249,8 +281,8 @@ func FavoritePost(c *gin.Context) {
 	}
 
 	userId := c.GetUint("uid")
-	if err := models.FavoritePost(uint(postId), userId); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := models.FavoritePost(c.Request.Context(), uint(postId), userId); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -267,8 +299,8 @@ func UnfavoritePost(c *gin.Context) {
 	}
 
 	userId := c.GetUint("uid")
-	if err := models.UnfavoritePost(uint(postId), userId); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := models.UnfavoritePost(c.Request.Context(), uint(postId), userId); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -306,9 +338,9 @@ func GetPostStatus(c *gin.Context) {
 
 	userID := c.GetUint("uid")
 
-	status, err := models.GetUserPostStatuses(userID, postIDs)
+	status, err := models.GetUserPostStatuses(c.Request.Context(), userID, postIDs)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		c.Error(err)
 		return
 	}
 
@@ -322,7 +354,7 @@ func GetPostStatus(c *gin.Context) {
 		favoritedSet[id] = true
 	}
 
-	following, err := models.GetUserFollowStatusForPosts(userID, postIDs)
+	following, err := models.GetUserFollowStatusForPosts(c.Request.Context(), userID, postIDs)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to get follow status", nil)
 		return
@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"hyperlane/models"
+	"hyperlane/policy"
 	"hyperlane/utils"
 	"net/http"
 	"strconv"
@@ -29,7 +30,7 @@ func CreateReacp(c *gin.Context) {
 	var event models.Event
 	event.ID = req.EventId
 
-	if err := event.GetByID(req.EventId); err != nil {
+	if err := event.GetByID(c.Request.Context(), req.EventId); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "event is not exist!", nil)
 		return
 	}
@@ -48,8 +49,8 @@ func CreateReacp(c *gin.Context) {
 
 	recap.UserId = userId
 	// 创建数据库记录
-	if err := recap.Create(); err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error(), nil)
+	if err := recap.Create(c.Request.Context()); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -61,7 +62,7 @@ func GetRecap(c *gin.Context) {
 
 	var event models.Event
 	event.ID = uint(eventId)
-	if err := event.GetByID(uint(eventId)); err != nil {
+	if err := event.GetByID(c.Request.Context(), uint(eventId)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "event is not exist!", nil)
 		return
 	}
@@ -69,7 +70,7 @@ func GetRecap(c *gin.Context) {
 	var recap models.Recap
 	recap.EventId = event.ID
 
-	if err := recap.GetByEventId(event.ID); err != nil {
+	if err := recap.GetByEventId(c.Request.Context(), event.ID); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "recap is not exist!", nil)
 		return
 	}
@@ -87,27 +88,19 @@ func UpdateRecap(c *gin.Context) {
 
 	var req UpdateRecapRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid input data", nil)
+		c.Error(err)
 		return
 	}
 
 	var recap models.Recap
 	recap.ID = uint(id)
 
-	if err = recap.GetByID(uint(id)); err != nil {
+	if err = recap.GetByID(c.Request.Context(), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid recap", nil)
 		return
 	}
 
-	uid, ok := c.Get("uid")
-	if !ok {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
-		return
-	}
-
-	userId, _ := uid.(uint)
-	if userId != recap.UserId {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "not author", nil)
+	if !authorize(c, policy.Recap, policy.Update, recap.UserId) {
 		return
 	}
 
@@ -116,7 +109,7 @@ func UpdateRecap(c *gin.Context) {
 	recap.Recording = req.Recording
 	recap.Twitter = req.Twitter
 
-	if err := recap.Update(); err != nil {
+	if err := recap.Update(c.Request.Context()); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update recap", nil)
 		return
 	}
@@ -137,7 +130,7 @@ func DeleteRecap(c *gin.Context) {
 	var event models.Event
 	event.ID = uint(eventId)
 
-	if err := event.GetByID(uint(eventId)); err != nil {
+	if err := event.GetByID(c.Request.Context(), uint(eventId)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "event is not exist!", nil)
 		return
 	}
@@ -145,24 +138,16 @@ func DeleteRecap(c *gin.Context) {
 	var recap models.Recap
 	recap.ID = uint(id)
 
-	if err = recap.GetByID(uint(id)); err != nil {
+	if err = recap.GetByID(c.Request.Context(), uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid recap", nil)
 		return
 	}
 
-	uid, ok := c.Get("uid")
-	if !ok {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
-		return
-	}
-
-	userId, _ := uid.(uint)
-	if userId != recap.UserId {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "not author", nil)
+	if !authorize(c, policy.Recap, policy.Delete, recap.UserId) {
 		return
 	}
 
-	if err := recap.Delete(); err != nil {
+	if err := recap.Delete(c.Request.Context()); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete recap", nil)
 		return
 	}
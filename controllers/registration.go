@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hyperlane/metrics"
+	"hyperlane/models"
+	"hyperlane/policy"
+	"hyperlane/utils"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterEvent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	var req RegisterEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(err)
+		return
+	}
+
+	userId := c.GetUint("uid")
+	user, err := models.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	reg := models.EventRegistration{
+		EventId: uint(id),
+		UserId:  userId,
+		Name:    req.Name,
+		Email:   req.Email,
+	}
+	if reg.Name == "" {
+		reg.Name = user.Username
+	}
+	if reg.Email == "" {
+		reg.Email = user.Email
+	}
+
+	if err := models.RegisterEvent(c.Request.Context(), &reg); err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := "registered"
+	if reg.Status == models.RegistrationStatusWaitlisted {
+		status = "waitlisted"
+	}
+	metrics.Registrations.WithLabelValues(status).Inc()
+	utils.SuccessResponse(c, http.StatusOK, "register success", reg)
+}
+
+func CancelEventRegistration(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	userId := c.GetUint("uid")
+	if err := models.CancelEventRegistration(c.Request.Context(), uint(id), userId); err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "cancel success", nil)
+}
+
+// 当前用户的报名状态
+func GetMyEventRegistration(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	reg, err := models.GetEventRegistration(c.Request.Context(), uint(id), c.GetUint("uid"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "registration not found", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", reg)
+}
+
+func QueryEventRegistrations(c *gin.Context) {
+	event, ok := getOrganizedEvent(c)
+	if !ok {
+		return
+	}
+
+	status, _ := strconv.Atoi(c.DefaultQuery("status", "0"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+
+	filter := models.RegistrationFilter{
+		EventId:  event.ID,
+		Status:   status,
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	regs, total, err := models.QueryEventRegistrations(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var response = QueryRegistrationsResponse{
+		Registrations: regs,
+		Page:          page,
+		PageSize:      pageSize,
+		Total:         total,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
+
+// 导出报名名单（CSV）
+func ExportEventRegistrations(c *gin.Context) {
+	event, ok := getOrganizedEvent(c)
+	if !ok {
+		return
+	}
+
+	status, _ := strconv.Atoi(c.DefaultQuery("status", "0"))
+	regs, _, err := models.QueryEventRegistrations(c.Request.Context(), models.RegistrationFilter{
+		EventId: event.ID,
+		Status:  status,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	statusNames := map[uint]string{
+		models.RegistrationStatusRegistered: "registered",
+		models.RegistrationStatusWaitlisted: "waitlisted",
+		models.RegistrationStatusCancelled:  "cancelled",
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=event-%d-registrations.csv", event.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"id", "user_id", "name", "email", "status", "registered_at"})
+	for _, r := range regs {
+		w.Write([]string{
+			strconv.FormatUint(uint64(r.ID), 10),
+			strconv.FormatUint(uint64(r.UserId), 10),
+			utils.CSVCell(r.Name),
+			utils.CSVCell(r.Email),
+			statusNames[r.Status],
+			r.RegisteredAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	w.Flush()
+}
+
+// 取出活动并校验当前用户是活动创建者
+func getOrganizedEvent(c *gin.Context) (*models.Event, bool) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return nil, false
+	}
+
+	var event models.Event
+	if err := event.GetByID(c.Request.Context(), uint(id)); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Event", nil)
+		return nil, false
+	}
+
+	if !authorize(c, policy.Event, policy.Registrations, event.UserId) {
+		return nil, false
+	}
+
+	return &event, true
+}
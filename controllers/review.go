@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/policy"
+	"hyperlane/utils"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 审核流程接口按内容类型（blog / event）复用，权限前缀与类型一致，如 blog:review、blog:publish
+
+func TransitionContent(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		var req ReviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		review, err := models.TransitionContent(c.Request.Context(), targetType, uint(id), reviewActor(c, targetType), req.Action, req.Note)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "success", review)
+	}
+}
+
+// 审核历史仅作者和有审核、发布权限的用户可见
+func QueryContentReviews(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		ownerId, err := models.ReviewOwner(c.Request.Context(), targetType, uint(id))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		if !authorize(c, targetType, policy.History, ownerId) {
+			return
+		}
+
+		reviews, err := models.QueryContentReviews(c.Request.Context(), targetType, uint(id))
+		if err != nil {
+			logger.Log.Errorf("query reviews failed: %v", err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "query success", reviews)
+	}
+}
+
+// 当前用户对该类型内容的审核、发布权限
+func reviewActor(c *gin.Context, targetType string) models.ReviewActor {
+	permissions := c.GetStringSlice("permissions")
+	return models.ReviewActor{
+		UserId:     c.GetUint("uid"),
+		CanReview:  slices.Contains(permissions, targetType+":review"),
+		CanPublish: slices.Contains(permissions, targetType+":publish"),
+	}
+}
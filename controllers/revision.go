@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/policy"
+	"hyperlane/utils"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 修订接口仅作者和博客审核、发布人员可用，路由中的 :id 为博客 ID
+
+// 解析路由参数并校验访问权限，失败时已写入响应
+func revisionAccess(c *gin.Context) (articleId uint, ownerId uint, ok bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return 0, 0, false
+	}
+
+	ownerId, err = models.ReviewOwner(c.Request.Context(), models.CommentTargetBlog, uint(id))
+	if err != nil {
+		c.Error(err)
+		return 0, 0, false
+	}
+	if !authorize(c, policy.Blog, policy.History, ownerId) {
+		return 0, 0, false
+	}
+	return uint(id), ownerId, true
+}
+
+func QueryArticleRevisions(c *gin.Context) {
+	articleId, _, ok := revisionAccess(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter := models.ArticleRevisionFilter{
+		ArticleId: articleId,
+		Page:      page,
+		PageSize:  pageSize,
+	}
+
+	revisions, total, err := models.QueryArticleRevisions(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.Errorf("query revisions failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	var response = QueryRevisionsResponse{
+		Revisions: revisions,
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     total,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
+
+func GetArticleRevision(c *gin.Context) {
+	articleId, _, ok := revisionAccess(c)
+	if !ok {
+		return
+	}
+	revisionId, err := strconv.Atoi(c.Param("revision_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	rev, err := models.GetArticleRevision(c.Request.Context(), articleId, uint(revisionId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", rev)
+}
+
+// 对比两个修订，against 为空时与上一个版本比较
+func DiffArticleRevision(c *gin.Context) {
+	articleId, _, ok := revisionAccess(c)
+	if !ok {
+		return
+	}
+	revisionId, err := strconv.Atoi(c.Param("revision_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	to, err := models.GetArticleRevision(c.Request.Context(), articleId, uint(revisionId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var from *models.ArticleRevision
+	if against := c.Query("against"); against != "" {
+		againstId, err := strconv.Atoi(against)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid against", nil)
+			return
+		}
+		from, err = models.GetArticleRevision(c.Request.Context(), articleId, uint(againstId))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	} else if from, err = models.GetPreviousArticleRevision(c.Request.Context(), to); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 第一个版本与空内容比较
+	var fromContent models.ArticleContent
+	var response = RevisionDiffResponse{ToVersion: to.Version}
+	if from != nil {
+		fromContent = from.ArticleContent
+		response.FromVersion = from.Version
+	}
+
+	response.Changes = diffArticleFields(fromContent, to.ArticleContent)
+	response.Lines = utils.DiffLines(fromContent.Content, to.Content)
+
+	utils.SuccessResponse(c, http.StatusOK, "success", response)
+}
+
+// 正文以外发生变化的字段
+func diffArticleFields(from, to models.ArticleContent) []FieldChange {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"category", from.Category, to.Category},
+		{"cover_img", from.CoverImg, to.CoverImg},
+		{"source_link", from.SourceLink, to.SourceLink},
+		{"source_type", from.SourceType, to.SourceType},
+		{"author", from.Author, to.Author},
+		{"translator", from.Translator, to.Translator},
+	}
+
+	var changes []FieldChange
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, FieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	if !slices.Equal(from.Tags, to.Tags) {
+		changes = append(changes, FieldChange{
+			Field: "tags",
+			From:  strings.Join(from.Tags, ","),
+			To:    strings.Join(to.Tags, ","),
+		})
+	}
+	return changes
+}
+
+// 作者恢复历史版本，恢复的内容作为一次新的修改
+func RestoreArticleRevision(c *gin.Context) {
+	articleId, ownerId, ok := revisionAccess(c)
+	if !ok {
+		return
+	}
+	if ownerId != c.GetUint("uid") {
+		utils.ErrorResponse(c, http.StatusForbidden, "not author", nil)
+		return
+	}
+	revisionId, err := strconv.Atoi(c.Param("revision_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	article, _, err := models.RestoreArticleRevision(c.Request.Context(), articleId, uint(revisionId), c.GetUint("uid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "success", article)
+}
+
+// 审核已发布博客的待审核修订，approve 为 false 时退回并必须填写意见
+func ReviewArticleRevision(approve bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+		revisionId, err := strconv.Atoi(c.Param("revision_id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+			return
+		}
+
+		var req ReviewRevisionRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.Error(err)
+			return
+		}
+
+		rev, err := models.ReviewArticleRevision(c.Request.Context(), uint(id), uint(revisionId), c.GetUint("uid"), approve, req.Note)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusOK, "success", rev)
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Search(c *gin.Context) {
+	keyword := strings.TrimSpace(c.Query("q"))
+	if keyword == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "q parameter required", nil)
+		return
+	}
+
+	searchType := c.Query("type")
+	switch searchType {
+	case "", models.SearchTypePost, models.SearchTypeBlog, models.SearchTypeEvent:
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "invalid type", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter := models.SearchFilter{
+		Keyword:  keyword,
+		Type:     searchType,
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	result, err := models.Search(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.Errorf("search failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "search failed", nil)
+		return
+	}
+
+	var response = SearchResponse{
+		SearchResult: result,
+		Page:         page,
+		PageSize:     pageSize,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
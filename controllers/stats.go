@@ -9,9 +9,9 @@ import (
 )
 
 func StatsOverview(c *gin.Context) {
-	overview, err := models.GetStatsOverview()
+	overview, err := models.GetStatsOverview(c.Request.Context())
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "success fail", nil)
+		c.Error(err)
 		return
 	}
 	utils.SuccessResponse(c, http.StatusOK, "query success", overview)
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/storage"
+	"hyperlane/utils"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// 上传图片，表单字段 file；相同内容重复上传直接返回已有记录
+func UploadImage(c *gin.Context) {
+	uid := c.GetUint("uid")
+	maxSize := viper.GetInt64("uploads.maxSize")
+
+	// 多留 1MB 给 multipart 头部
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "file too large", nil)
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, "missing file", nil)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxSize {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "file too large", nil)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "read file failed", nil)
+		return
+	}
+	if int64(len(data)) > maxSize {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "file too large", nil)
+		return
+	}
+
+	info, err := utils.DetectImage(data)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "only jpeg, png, gif and webp images are allowed", nil)
+		return
+	}
+	if info.Width < viper.GetInt("uploads.minWidth") || info.Height < viper.GetInt("uploads.minHeight") ||
+		info.Width > viper.GetInt("uploads.maxWidth") || info.Height > viper.GetInt("uploads.maxHeight") {
+		utils.ErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("image dimensions %dx%d out of range", info.Width, info.Height), nil)
+		return
+	}
+
+	hash := utils.HashContent(data)
+	existing, err := models.GetUserUploadByHash(c.Request.Context(), uid, hash)
+	if err != nil {
+		logger.Log.Errorf("query upload failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+	if existing != nil {
+		utils.SuccessResponse(c, http.StatusOK, "upload success", existing)
+		return
+	}
+
+	upload := &models.Upload{
+		UserId:   uid,
+		Hash:     hash,
+		MimeType: info.MimeType,
+		Size:     int64(len(data)),
+		Width:    info.Width,
+		Height:   info.Height,
+	}
+
+	// 其他用户上传过相同内容时复用已存储的对象
+	shared, err := models.GetUploadByHash(c.Request.Context(), hash)
+	if err != nil {
+		logger.Log.Errorf("query upload failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+	if shared != nil {
+		upload.Key, upload.URL = shared.Key, shared.URL
+		upload.ThumbKey, upload.ThumbURL = shared.ThumbKey, shared.ThumbURL
+	} else if err := storeImage(c.Request.Context(), upload, data, info.Ext); err != nil {
+		logger.Log.Errorf("store upload failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "store file failed", nil)
+		return
+	}
+
+	upload, err = models.CreateUpload(c.Request.Context(), upload)
+	if err != nil {
+		logger.Log.Errorf("create upload failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "upload success", upload)
+}
+
+// 写入原图和缩略图，对象 key 由内容哈希决定
+func storeImage(ctx context.Context, upload *models.Upload, data []byte, ext string) error {
+	store := storage.Default()
+	dir := upload.Hash[:2]
+
+	upload.Key = fmt.Sprintf("images/%s/%s%s", dir, upload.Hash, ext)
+	if err := store.Put(ctx, upload.Key, bytes.NewReader(data), int64(len(data)), upload.MimeType); err != nil {
+		return err
+	}
+	upload.URL = store.URL(upload.Key)
+
+	thumb, thumbType, err := utils.Thumbnail(data, viper.GetInt("uploads.thumbSize"))
+	if err != nil {
+		// 缩略图失败不影响原图使用
+		logger.Log.Warnf("generate thumbnail failed: %v", err)
+		return nil
+	}
+	thumbExt := ".jpg"
+	if thumbType == "image/png" {
+		thumbExt = ".png"
+	}
+	upload.ThumbKey = fmt.Sprintf("thumbs/%s/%s%s", dir, upload.Hash, thumbExt)
+	if err := store.Put(ctx, upload.ThumbKey, bytes.NewReader(thumb), int64(len(thumb)), thumbType); err != nil {
+		return err
+	}
+	upload.ThumbURL = store.URL(upload.ThumbKey)
+	return nil
+}
+
+func QueryUploads(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	filter := models.UploadFilter{
+		UserId:   c.GetUint("uid"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	uploads, total, err := models.QueryUploads(c.Request.Context(), filter)
+	if err != nil {
+		logger.Log.Errorf("query uploads failed: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
+		return
+	}
+
+	var response = QueryUploadsResponse{
+		Uploads:  uploads,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "query success", response)
+}
+
+// 删除自己的上传，仍被头像或封面引用时不允许删除
+func DeleteUpload(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid ID", nil)
+		return
+	}
+
+	upload, unused, err := models.DeleteUserUpload(c.Request.Context(), c.GetUint("uid"), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if unused {
+		removeUploadObjects(c.Request.Context(), upload)
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "delete success", nil)
+}
+
+func removeUploadObjects(ctx context.Context, upload *models.Upload) {
+	for _, key := range []string{upload.Key, upload.ThumbKey} {
+		if key == "" {
+			continue
+		}
+		if err := storage.Default().Delete(ctx, key); err != nil {
+			logger.Log.Errorf("delete object %s failed: %v", key, err)
+		}
+	}
+}
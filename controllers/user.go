@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"hyperlane/logger"
+	"hyperlane/metrics"
 	"hyperlane/models"
+	"hyperlane/policy"
 	"hyperlane/utils"
 	"net/http"
 	"strconv"
@@ -18,7 +20,7 @@ func GetUser(c *gin.Context) {
 		return
 	}
 
-	user, err := models.GetUserById(uint(id))
+	user, err := models.GetUserById(c.Request.Context(), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Article", nil)
 		return
@@ -42,30 +44,24 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := models.GetUserById(uint(id))
+	user, err := models.GetUserById(c.Request.Context(), uint(id))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "no user", nil)
 		return
 	}
 
-	uid, ok := c.Get("uid")
-	if !ok {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized", nil)
-		return
-	}
-	userId, _ := uid.(uint)
-
-	if user.ID != userId {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "permission denied.", nil)
+	if !authorize(c, policy.User, policy.Update, user.ID) {
 		return
 	}
 
 	user.Email = req.Email
 	user.Username = req.Username
-	user.Avatar = req.Avatar
+	if req.Avatar != "" {
+		user.Avatar = req.Avatar
+	}
 	user.Github = req.Github
 
-	if err := models.UpdateUser(user); err != nil {
+	if err := models.UpdateUser(c.Request.Context(), user); err != nil {
 		utils.ErrorResponse(c, http.StatusUnauthorized, "update fail", nil)
 		return
 	}
@@ -97,14 +93,14 @@ func FollowUser(c *gin.Context) {
 	}
 
 	// 检查目标用户是否存在
-	_, err = models.GetUserById(followingID)
+	_, err = models.GetUserById(c.Request.Context(), followingID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "target user not found", nil)
 		return
 	}
 
 	// 检查是否已关注
-	isFollowing, err := models.IsFollowing(followerID, followingID)
+	isFollowing, err := models.IsFollowing(c.Request.Context(), followerID, followingID)
 	if err != nil {
 		logger.Log.Errorf("check follow failed: %v", err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
@@ -115,12 +111,13 @@ func FollowUser(c *gin.Context) {
 		return
 	}
 
-	if err := models.FollowUser(followerID, followingID); err != nil {
+	if err := models.FollowUser(c.Request.Context(), followerID, followingID); err != nil {
 		logger.Log.Errorf("failed to follow: %v", err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to follow", nil)
 		return
 	}
 
+	metrics.Follows.Inc()
 	utils.SuccessResponse(c, http.StatusOK, "follow success", nil)
 }
 
@@ -146,14 +143,14 @@ func UnfollowUser(c *gin.Context) {
 	}
 
 	// 检查目标用户是否存在
-	_, err = models.GetUserById(followingID)
+	_, err = models.GetUserById(c.Request.Context(), followingID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "target user not found", nil)
 		return
 	}
 
 	// 检查是否已关注
-	isFollowing, err := models.IsFollowing(followerID, followingID)
+	isFollowing, err := models.IsFollowing(c.Request.Context(), followerID, followingID)
 	if err != nil {
 		logger.Log.Errorf("check follow failed: %v", err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
@@ -165,7 +162,7 @@ func UnfollowUser(c *gin.Context) {
 	}
 
 	// 取消关注		
-	if err := models.UnfollowUser(followerID, followingID); err != nil {
+	if err := models.UnfollowUser(c.Request.Context(), followerID, followingID); err != nil {
 		logger.Log.Errorf("failed to unfollow: %v", err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "failed to unfollow", nil)
 		return
@@ -177,7 +174,7 @@ func UnfollowUser(c *gin.Context) {
 func GetFollowStates(c *gin.Context) {
 	var req FollowStatesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "invalid request", nil)
+		c.Error(err)
 		return
 	}
 
@@ -188,7 +185,7 @@ func GetFollowStates(c *gin.Context) {
 	}
 	followerID, _ := uid.(uint)
 
-	states, err := models.GetFollowingStates(followerID, req.UserIDs)
+	states, err := models.GetFollowingStates(c.Request.Context(), followerID, req.UserIDs)
 	if err != nil {
 		logger.Log.Errorf("get follow states failed: %v", err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "internal error", nil)
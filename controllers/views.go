@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"hyperlane/views"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordView 记录一次详情浏览，登录用户按用户去重，匿名访客按 IP 去重；返回是否计入浏览量
+func recordView(c *gin.Context, targetType string, id uint) bool {
+	viewer := "ip:" + c.ClientIP()
+	if uid, ok := c.Get("uid"); ok {
+		viewer = "u:" + strconv.FormatUint(uint64(uid.(uint)), 10)
+	}
+	return views.Record(targetType, id, viewer)
+}
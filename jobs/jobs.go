@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/ratelimit"
+)
+
+// Job 一个可调度的后台任务
+type Job struct {
+	Name     string                          // 任务名，同时作为配置 key 和 advisory lock 的来源
+	Schedule string                          // 默认调度（cron 表达式），可由 jobs.<name> 覆盖
+	Run      func(ctx context.Context) error // 任务逻辑
+}
+
+// 已注册的任务，新增任务在这里追加即可
+var registry = []Job{
+	{
+		Name:     "daily_stats",
+		Schedule: "5 0 * * *",
+		Run: func(ctx context.Context) error {
+			return models.CollectDailyStats(ctx)
+		},
+	},
+	{
+		Name:     "event_status",
+		Schedule: "*/5 * * * *",
+		Run: func(ctx context.Context) error {
+			n, err := models.UpdateEventStatuses(ctx, time.Now())
+			if n > 0 {
+				logger.Log.Infof("event status updated: %d", n)
+			}
+			return err
+		},
+	},
+	{
+		Name:     "token_cleanup",
+		Schedule: "0 4 * * *",
+		Run: func(ctx context.Context) error {
+			return models.DeleteExpiredTokens(ctx, time.Now())
+		},
+	},
+	{
+		Name:     "job_runs_cleanup",
+		Schedule: "30 3 * * *",
+		Run: func(ctx context.Context) error {
+			_, err := models.DeleteJobRunsBefore(ctx, time.Now().AddDate(0, 0, -30))
+			return err
+		},
+	},
+	{
+		Name:     "upload_gc",
+		Schedule: "0 5 * * *",
+		Run: func(ctx context.Context) error {
+			// 留一天给客户端上传后再提交表单
+			return collectOrphanUploads(ctx, time.Now().Add(-24*time.Hour))
+		},
+	},
+	{
+		Name:     "feed_scores",
+		Schedule: "*/5 * * * *",
+		Run: func(ctx context.Context) error {
+			_, err := models.RefreshFeedScores(ctx, time.Now(), models.LoadFeedRanking())
+			return err
+		},
+	},
+	{
+		Name:     "rate_limit_cleanup",
+		Schedule: "15 * * * *",
+		Run: func(ctx context.Context) error {
+			// 只有 postgres 存储需要清理，内存存储自行回收
+			store, ok := ratelimit.Default().(*ratelimit.Postgres)
+			if !ok {
+				return nil
+			}
+			_, err := store.DeleteIdle(ctx, time.Now().Add(-time.Hour))
+			return err
+		},
+	},
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+
+	"hyperlane/logger"
+	"hyperlane/models"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/viper"
+)
+
+// Start 按配置启动所有已注册任务，jobs.<name> 为 "off" 时禁用该任务。
+// 任务在 ctx 下执行，停机时取消 ctx 通知正在执行的任务尽快退出
+func Start(ctx context.Context) *cron.Cron {
+	c := cron.New()
+	host, _ := os.Hostname()
+
+	for _, job := range registry {
+		schedule := viper.GetString("jobs." + job.Name)
+		if schedule == "" {
+			schedule = job.Schedule
+		}
+		if schedule == "off" {
+			logger.Log.Infof("job %s disabled", job.Name)
+			continue
+		}
+
+		job := job
+		_, err := c.AddFunc(schedule, func() {
+			runJob(ctx, job, host)
+		})
+		if err != nil {
+			logger.Log.Errorf("invalid schedule %q for job %s: %v", schedule, job.Name, err)
+			continue
+		}
+		logger.Log.Infof("job %s scheduled: %s", job.Name, schedule)
+	}
+
+	c.Start()
+	return c
+}
+
+// 多副本部署时通过 advisory lock + 调度时刻唯一记录保证同一时刻只执行一次
+func runJob(ctx context.Context, job Job, host string) {
+	slot := time.Now().Truncate(time.Minute)
+
+	unlock, ok, err := models.TryAdvisoryLock(ctx, lockKey(job.Name))
+	if err != nil {
+		logger.Log.Errorf("job %s: acquire lock failed: %v", job.Name, err)
+		return
+	}
+	if !ok {
+		logger.Log.Debugf("job %s: running on another instance", job.Name)
+		return
+	}
+	defer unlock()
+
+	run, created, err := models.StartJobRun(ctx, job.Name, slot, host)
+	if err != nil {
+		logger.Log.Errorf("job %s: record run failed: %v", job.Name, err)
+		return
+	}
+	if !created {
+		logger.Log.Debugf("job %s: already run at %s", job.Name, slot)
+		return
+	}
+
+	runErr := safeRun(ctx, job)
+	if runErr != nil {
+		logger.Log.Errorf("job %s failed: %v", job.Name, runErr)
+	}
+	// 停机时 ctx 已取消，执行结果仍需写入
+	if err := run.Finish(context.WithoutCancel(ctx), runErr); err != nil {
+		logger.Log.Errorf("job %s: save run failed: %v", job.Name, err)
+	}
+}
+
+func safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("job:" + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"hyperlane/logger"
+	"hyperlane/models"
+	"hyperlane/storage"
+)
+
+// 每批处理的孤儿文件数
+const uploadGCBatch = 100
+
+// 清理 before 之前上传、至今未被任何内容引用的文件；同一内容还有其他上传者时只删记录
+func collectOrphanUploads(ctx context.Context, before time.Time) error {
+	store := storage.Default()
+	removed := 0
+	for {
+		uploads, err := models.QueryOrphanUploads(ctx, before, uploadGCBatch)
+		if err != nil {
+			return err
+		}
+		for i := range uploads {
+			unused, err := models.DeleteOrphanUpload(ctx, &uploads[i])
+			if err != nil {
+				return err
+			}
+			removed++
+			if !unused {
+				continue
+			}
+			for _, key := range []string{uploads[i].Key, uploads[i].ThumbKey} {
+				if key == "" {
+					continue
+				}
+				if err := store.Delete(ctx, key); err != nil {
+					logger.Log.Errorf("delete object %s failed: %v", key, err)
+				}
+			}
+		}
+		if len(uploads) < uploadGCBatch || ctx.Err() != nil {
+			break
+		}
+	}
+	if removed > 0 {
+		logger.Log.Infof("orphan uploads removed: %d", removed)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger 把 GORM 的 SQL 日志写入 logrus，带上 context 中的请求 ID；
+// 只记录出错和慢查询，Debug 级别时记录全部 SQL
+type GormLogger struct {
+	SlowThreshold time.Duration
+}
+
+func NewGormLogger(slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{SlowThreshold: slowThreshold}
+}
+
+// 日志级别跟随 logrus，LogMode 保留接口要求
+func (l *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	WithContext(ctx).Infof(msg, args...)
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	WithContext(ctx).Warnf(msg, args...)
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	WithContext(ctx).Errorf(msg, args...)
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	slow := l.SlowThreshold > 0 && elapsed > l.SlowThreshold
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	if !failed && !slow && !Log.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+
+	sql, rows := fc()
+	entry := WithContext(ctx).WithFields(logrus.Fields{
+		"sql":        sql,
+		"rows":       rows,
+		"latency_ms": float64(elapsed.Microseconds()) / 1000,
+	})
+	switch {
+	case failed:
+		entry.WithError(err).Error("sql error")
+	case slow:
+		entry.Warn("slow sql")
+	default:
+		entry.Debug("sql")
+	}
+}
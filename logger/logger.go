@@ -1,32 +1,77 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var Log *logrus.Logger
+var Log = logrus.New()
 
-func Init(logFile string, level string) {
-	Log = logrus.New()
+// Options 日志配置，文件按大小切割，超过保留天数或份数的旧文件自动删除
+type Options struct {
+	File       string
+	Level      string
+	MaxSize    int  // 单个文件大小上限（MB）
+	MaxAge     int  // 旧文件保留天数，0 表示不按时间删除
+	MaxBackups int  // 旧文件保留份数，0 表示不按份数删除
+	Compress   bool // 是否 gzip 压缩旧文件
+}
 
-	// 自动创建父目录
-	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
-		Log.Out = os.Stdout
-	} else {
-		file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-		if err != nil {
-			Log.Out = os.Stdout
-		} else {
-			Log.Out = file
-		}
-	}
+func Init(opts Options) {
+	Log = logrus.New()
+	Log.SetFormatter(&logrus.JSONFormatter{})
+	Log.Out = openOutput(opts)
 
-	logLevel, err := logrus.ParseLevel(level)
+	logLevel, err := logrus.ParseLevel(opts.Level)
 	if err != nil {
 		logLevel = logrus.InfoLevel
 	}
 	Log.SetLevel(logLevel)
 }
+
+func openOutput(opts Options) io.Writer {
+	if opts.File == "" {
+		return os.Stdout
+	}
+	// 自动创建父目录
+	if err := os.MkdirAll(filepath.Dir(opts.File), 0755); err != nil {
+		return os.Stdout
+	}
+	return &lumberjack.Logger{
+		Filename:   opts.File,
+		MaxSize:    opts.MaxSize,
+		MaxAge:     opts.MaxAge,
+		MaxBackups: opts.MaxBackups,
+		Compress:   opts.Compress,
+		LocalTime:  true,
+	}
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID 把请求 ID 放入 context，之后经该 context 输出的日志都带上 request_id
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithContext 返回带 request_id 字段的日志条目，context 中没有请求 ID 时不加字段
+func WithContext(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(Log)
+	if id := RequestIDFromContext(ctx); id != "" {
+		entry = entry.WithField("request_id", id)
+	}
+	return entry
+}
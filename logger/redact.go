@@ -0,0 +1,28 @@
+package logger
+
+import "regexp"
+
+const redacted = "[REDACTED]"
+
+var (
+	// JSON 或 Go 结构体输出中的敏感字段，如 "access_token":"..."、ClientSecret:xxx
+	sensitiveField = regexp.MustCompile(`(?i)("?[a-z_]*(?:token|secret|password|authorization)"?\s*[:=]\s*)("(?:[^"\\]|\\.)*"|[^\s,}\]&]+)`)
+	// Authorization 头中的凭证
+	bearerToken = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[a-z0-9._~+/=-]+`)
+)
+
+// Redact 隐去日志内容中的令牌、密钥和密码，保留字段名便于排查；
+// 先替换 Authorization 头中的凭证，避免 Go 结构体输出时凭证与字段值之间的空格导致漏替换
+func Redact(s string) string {
+	s = bearerToken.ReplaceAllString(s, "$1 "+redacted)
+	return sensitiveField.ReplaceAllStringFunc(s, func(m string) string {
+		parts := sensitiveField.FindStringSubmatch(m)
+		if parts[2] == `""` {
+			return m
+		}
+		if parts[2][0] == '"' {
+			return parts[1] + `"` + redacted + `"`
+		}
+		return parts[1] + redacted
+	})
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "JSON token fields",
+			input:    `{"access_token":"abc.def","token_type":"bearer","expires_in":3600}`,
+			expected: `{"access_token":"[REDACTED]","token_type":"bearer","expires_in":3600}`,
+		},
+		{
+			name:     "Escaped quote inside value",
+			input:    `{"client_secret":"a\"b","ok":true}`,
+			expected: `{"client_secret":"[REDACTED]","ok":true}`,
+		},
+		{
+			name:     "Go struct output",
+			input:    `{ClientId:app ClientSecret:s3cr3t Code:xyz}`,
+			expected: `{ClientId:app ClientSecret:[REDACTED] Code:xyz}`,
+		},
+		{
+			name:     "Authorization header in map",
+			input:    `map[Accept:application/json Authorization:Basic YXBwOnNlY3JldA==]`,
+			expected: `map[Accept:application/json Authorization:[REDACTED] [REDACTED]]`,
+		},
+		{
+			name:     "Bearer token in text",
+			input:    `calling api with Bearer eyJhbGciOiJIUzI1NiJ9.e30.sig`,
+			expected: `calling api with Bearer [REDACTED]`,
+		},
+		{
+			name:     "Query string password",
+			input:    `/login?user=bob&password=hunter2&next=/`,
+			expected: `/login?user=bob&password=[REDACTED]&next=/`,
+		},
+		{
+			name:     "Empty value kept",
+			input:    `{"refresh_token":""}`,
+			expected: `{"refresh_token":""}`,
+		},
+		{
+			name:     "Nothing sensitive",
+			input:    `{"uid":42,"username":"alice"}`,
+			expected: `{"uid":42,"username":"alice"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.input); got != tt.expected {
+				t.Errorf("Redact() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	entry := WithContext(context.Background())
+	if _, ok := entry.Data["request_id"]; ok {
+		t.Error("unexpected request_id without request context")
+	}
+
+	entry = WithContext(ContextWithRequestID(context.Background(), "req-1"))
+	if got := entry.Data["request_id"]; got != "req-1" {
+		t.Errorf("request_id = %v, want req-1", got)
+	}
+}
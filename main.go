@@ -1,22 +1,31 @@
 package main
 
 import (
-	"hyperlane/logger"
-	"hyperlane/middlewares"
-	"hyperlane/routes"
+	"flag"
+	"log"
+	_ "time/tzdata" // 内置时区数据，calendar.timezone 不依赖系统 tzdata
 
-	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper"
+	"hyperlane/app"
 )
 
 func main() {
-	// 初始化日志
-	logFile := viper.GetString("log.file")
-	logLevel := viper.GetString("log.level")
-	logger.Init(logFile, logLevel)
+	configPath := flag.String("config", "", "配置文件路径，默认读取当前目录下的 config.yaml")
+	flag.Parse()
 
-	r := gin.Default()
-	r.Use(middlewares.Cors())
-	routes.SetupRouter(r)
-	r.Run(":8080")
+	// migrate 子命令：hyperlane [-config path] migrate up | down [n] | status
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := app.Migrate(*configPath, args[1:]); err != nil {
+			log.Fatal("Migrate failed: ", err)
+		}
+		return
+	}
+
+	a, err := app.New(*configPath)
+	if err != nil {
+		log.Fatal("Failed to start: ", err)
+	}
+
+	if err := a.Run(); err != nil {
+		log.Fatal(err)
+	}
 }
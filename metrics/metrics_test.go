@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/api/v1/posts/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/metrics", gin.WrapH(Handler()))
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Route template", path: "/api/v1/posts/42"},
+		{name: "Unmatched route", path: "/nope/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.CollectAndCount(httpDuration)
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if got := testutil.CollectAndCount(httpDuration); got != before+1 {
+				t.Errorf("series count = %d, want %d", got, before+1)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	for _, s := range []string{
+		`hyperlane_http_request_duration_seconds_count{method="GET",route="/api/v1/posts/:id",status="200"} 1`,
+		`hyperlane_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("metrics output missing %s", s)
+		}
+	}
+}
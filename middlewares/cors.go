@@ -1,25 +1,249 @@
 package middlewares
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 )
 
-func Cors() gin.HandlerFunc {
+// CorsPolicy 跨域策略。AllowedOrigins 支持完整来源（https://www.example.com）、
+// 子域名通配（https://*.example.com，不含 example.com 本身）和 *（任意来源，不能与 AllowCredentials 同时使用）
+type CorsPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // 预检结果缓存时间
+}
+
+// CorsOverride 按路径前缀覆盖默认策略，未填写的字段沿用默认值
+type CorsOverride struct {
+	Prefix           string
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials *bool
+	MaxAge           *time.Duration
+}
+
+// LoadCorsConfig 读取 cors 默认策略和 cors.overrides
+func LoadCorsConfig() (CorsPolicy, []CorsOverride, error) {
+	var policy CorsPolicy
+	var overrides []CorsOverride
+	if err := viper.UnmarshalKey("cors", &policy); err != nil {
+		return policy, nil, fmt.Errorf("cors: %w", err)
+	}
+	if err := viper.UnmarshalKey("cors.overrides", &overrides); err != nil {
+		return policy, nil, fmt.Errorf("cors.overrides: %w", err)
+	}
+	return policy, overrides, nil
+}
+
+type corsRule struct {
+	prefix       string
+	allowAll     bool
+	origins      map[string]bool
+	suffixes     []originPattern // 子域名通配
+	methods      map[string]bool
+	allowMethods string
+	allowHeaders string
+	anyHeader    bool
+	expose       string
+	credentials  bool
+	maxAge       string
+}
+
+type originPattern struct {
+	scheme string
+	suffix string // 以 . 开头的域名后缀，含端口
+}
+
+// Cors 按策略处理跨域请求：来源不在白名单时不返回 CORS 头，预检请求直接 403
+func Cors(policy CorsPolicy, overrides ...CorsOverride) (gin.HandlerFunc, error) {
+	base, err := compileCors("", policy)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*corsRule, 0, len(overrides))
+	for _, o := range overrides {
+		rule, err := compileCors(o.Prefix, o.apply(policy))
+		if err != nil {
+			return nil, fmt.Errorf("cors override %s: %w", o.Prefix, err)
+		}
+		rules = append(rules, rule)
+	}
+
 	return func(c *gin.Context) {
-		method := c.Request.Method
-		origin := c.Request.Header.Get("Origin")
-		if origin != "" {
-			c.Header("Access-Control-Allow-Origin", origin) // 使用请求中的 Origin
-			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization")
-			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		rule := base
+		for _, r := range rules {
+			if strings.HasPrefix(c.Request.URL.Path, r.prefix) {
+				rule = r
+				break
+			}
+		}
+
+		// 响应内容随 Origin 变化，缓存需要区分
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !rule.allowOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if rule.allowAll && !rule.credentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if rule.credentials {
 			c.Header("Access-Control-Allow-Credentials", "true")
 		}
-		if method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
+
+		if !preflight {
+			if rule.expose != "" {
+				c.Header("Access-Control-Expose-Headers", rule.expose)
+			}
+			c.Next()
+			return
+		}
+
+		if !rule.methods[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))] {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", rule.allowMethods)
+		if rule.anyHeader {
+			if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+				c.Header("Access-Control-Allow-Headers", requested)
+			}
+		} else if rule.allowHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", rule.allowHeaders)
+		}
+		if rule.maxAge != "" {
+			c.Header("Access-Control-Max-Age", rule.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}, nil
+}
+
+func (o CorsOverride) apply(p CorsPolicy) CorsPolicy {
+	if o.AllowedOrigins != nil {
+		p.AllowedOrigins = o.AllowedOrigins
+	}
+	if o.AllowedMethods != nil {
+		p.AllowedMethods = o.AllowedMethods
+	}
+	if o.AllowedHeaders != nil {
+		p.AllowedHeaders = o.AllowedHeaders
+	}
+	if o.ExposedHeaders != nil {
+		p.ExposedHeaders = o.ExposedHeaders
+	}
+	if o.AllowCredentials != nil {
+		p.AllowCredentials = *o.AllowCredentials
+	}
+	if o.MaxAge != nil {
+		p.MaxAge = *o.MaxAge
+	}
+	return p
+}
+
+func compileCors(prefix string, p CorsPolicy) (*corsRule, error) {
+	rule := &corsRule{
+		prefix:      prefix,
+		origins:     make(map[string]bool),
+		methods:     make(map[string]bool),
+		expose:      strings.Join(p.ExposedHeaders, ", "),
+		credentials: p.AllowCredentials,
+	}
+
+	for _, o := range p.AllowedOrigins {
+		o = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(o), "/"))
+		switch {
+		case o == "*":
+			if p.AllowCredentials {
+				return nil, fmt.Errorf("origin * cannot be used with allowCredentials")
+			}
+			rule.allowAll = true
+		case strings.Contains(o, "://*."):
+			scheme, host, _ := strings.Cut(o, "://*.")
+			if host == "" || strings.Contains(host, "*") {
+				return nil, fmt.Errorf("invalid origin pattern %q", o)
+			}
+			rule.suffixes = append(rule.suffixes, originPattern{scheme: scheme, suffix: "." + host})
+		default:
+			u, err := url.Parse(o)
+			if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || strings.Contains(o, "*") {
+				return nil, fmt.Errorf("invalid origin %q", o)
+			}
+			rule.origins[o] = true
+		}
+	}
+
+	methods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}
+	if len(p.AllowedMethods) > 0 {
+		methods = make([]string, len(p.AllowedMethods))
+		for i, m := range p.AllowedMethods {
+			methods[i] = strings.ToUpper(m)
+		}
+	}
+	for _, m := range methods {
+		rule.methods[m] = true
+	}
+	rule.allowMethods = strings.Join(methods, ", ")
+
+	for _, h := range p.AllowedHeaders {
+		if h == "*" {
+			rule.anyHeader = true
+		}
+	}
+	rule.allowHeaders = strings.Join(p.AllowedHeaders, ", ")
+
+	if p.MaxAge > 0 {
+		rule.maxAge = strconv.Itoa(int(p.MaxAge.Seconds()))
+	}
+	return rule, nil
+}
+
+func (r *corsRule) allowOrigin(origin string) bool {
+	if r.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if r.origins[origin] {
+		return true
+	}
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok {
+		return false
+	}
+	for _, p := range r.suffixes {
+		if scheme == p.scheme && strings.HasSuffix(host, p.suffix) && len(host) > len(p.suffix) {
+			return true
 		}
-		c.Next()
 	}
+	return false
 }
@@ -0,0 +1,197 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+func TestCors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	public := false
+	handler, err := Cors(CorsPolicy{
+		AllowedOrigins:   []string{"https://www.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}, CorsOverride{
+		Prefix:           "/api/v1/feeds",
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: &public,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(handler)
+	r.GET("/api/v1/posts", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/api/v1/feeds/blogs/rss", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		headers     map[string]string
+		status      int
+		allowOrigin string
+		credentials string
+		allowMethod string
+	}{
+		{
+			name:   "No origin",
+			method: "GET", path: "/api/v1/posts",
+			status: http.StatusOK,
+		},
+		{
+			name:   "Allowed origin",
+			method: "GET", path: "/api/v1/posts",
+			headers:     map[string]string{"Origin": "https://www.example.com"},
+			status:      http.StatusOK,
+			allowOrigin: "https://www.example.com",
+			credentials: "true",
+		},
+		{
+			name:   "Wildcard subdomain",
+			method: "GET", path: "/api/v1/posts",
+			headers:     map[string]string{"Origin": "https://app.example.org"},
+			status:      http.StatusOK,
+			allowOrigin: "https://app.example.org",
+			credentials: "true",
+		},
+		{
+			name:   "Wildcard does not match apex",
+			method: "GET", path: "/api/v1/posts",
+			headers: map[string]string{"Origin": "https://example.org"},
+			status:  http.StatusOK,
+		},
+		{
+			name:   "Lookalike domain",
+			method: "GET", path: "/api/v1/posts",
+			headers: map[string]string{"Origin": "https://evilexample.org"},
+			status:  http.StatusOK,
+		},
+		{
+			name:   "Scheme must match",
+			method: "GET", path: "/api/v1/posts",
+			headers: map[string]string{"Origin": "http://www.example.com"},
+			status:  http.StatusOK,
+		},
+		{
+			name:   "Preflight allowed",
+			method: "OPTIONS", path: "/api/v1/posts",
+			headers:     map[string]string{"Origin": "https://www.example.com", "Access-Control-Request-Method": "POST"},
+			status:      http.StatusNoContent,
+			allowOrigin: "https://www.example.com",
+			credentials: "true",
+			allowMethod: "GET, POST",
+		},
+		{
+			name:   "Preflight disallowed origin",
+			method: "OPTIONS", path: "/api/v1/posts",
+			headers: map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": "POST"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:   "Preflight disallowed method",
+			method: "OPTIONS", path: "/api/v1/posts",
+			headers:     map[string]string{"Origin": "https://www.example.com", "Access-Control-Request-Method": "DELETE"},
+			status:      http.StatusForbidden,
+			allowOrigin: "https://www.example.com",
+			credentials: "true",
+		},
+		{
+			name:   "Override allows any origin without credentials",
+			method: "GET", path: "/api/v1/feeds/blogs/rss",
+			headers:     map[string]string{"Origin": "https://reader.app"},
+			status:      http.StatusOK,
+			allowOrigin: "*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+				t.Errorf("Allow-Credentials = %q, want %q", got, tt.credentials)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.allowMethod {
+				t.Errorf("Allow-Methods = %q, want %q", got, tt.allowMethod)
+			}
+			if tt.headers["Origin"] != "" && !strings.Contains(strings.Join(w.Header().Values("Vary"), ","), "Origin") {
+				t.Error("missing Vary: Origin")
+			}
+		})
+	}
+}
+
+func TestCorsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CorsPolicy
+	}{
+		{name: "Any origin with credentials", policy: CorsPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}},
+		{name: "Origin with path", policy: CorsPolicy{AllowedOrigins: []string{"https://example.com/app"}}},
+		{name: "Missing scheme", policy: CorsPolicy{AllowedOrigins: []string{"example.com"}}},
+		{name: "Nested wildcard", policy: CorsPolicy{AllowedOrigins: []string{"https://*.*.example.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Cors(tt.policy); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoadCorsConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+cors:
+  allowedOrigins: ["https://www.example.com"]
+  allowCredentials: true
+  maxAge: 12h
+  overrides:
+    - prefix: /api/v1/feeds
+      allowedOrigins: ["*"]
+      allowCredentials: false
+      maxAge: 1h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, overrides, err := LoadCorsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.MaxAge != 12*time.Hour || !policy.AllowCredentials || len(policy.AllowedOrigins) != 1 {
+		t.Errorf("policy = %+v", policy)
+	}
+	if len(overrides) != 1 || overrides[0].AllowCredentials == nil || *overrides[0].AllowCredentials ||
+		overrides[0].MaxAge == nil || *overrides[0].MaxAge != time.Hour {
+		t.Errorf("overrides = %+v", overrides)
+	}
+}
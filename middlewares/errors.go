@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"hyperlane/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler 统一输出处理器通过 c.Error 记录的错误，需注册在 LoggerMiddleware 之后，
+// 原始错误由访问日志记录，响应中只包含错误码和提示
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		utils.RenderError(c, c.Errors.Last().Err)
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/missing", func(c *gin.Context) { c.Error(gorm.ErrRecordNotFound) })
+	r.GET("/failed", func(c *gin.Context) { c.Error(errors.New("connection refused")) })
+	r.GET("/written", func(c *gin.Context) {
+		c.Error(errors.New("logged only"))
+		c.Status(http.StatusAccepted)
+		c.Writer.WriteHeaderNow()
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/missing", wantStatus: http.StatusNotFound},
+		{path: "/failed", wantStatus: http.StatusInternalServerError},
+		{path: "/written", wantStatus: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
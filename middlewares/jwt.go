@@ -10,39 +10,67 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func JWT(permission string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		if tokenString == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Please log in to continue!", nil)
-			c.Abort()
-			return
-		}
+// authError token 校验失败时返回给客户端的状态码和提示
+type authError struct {
+	status  int
+	message string
+}
 
-		parts := strings.Split(tokenString, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication failed, please try again.", nil)
-			c.Abort()
-			return
-		}
+// authenticate 校验 Authorization 中的 token：签名和有效期、是否已吊销、
+// token_version（退出所有设备）以及 token 中的权限是否仍与用户当前权限一致
+func authenticate(c *gin.Context) (*utils.Claims, *authError) {
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		return nil, &authError{http.StatusUnauthorized, "Please log in to continue!"}
+	}
 
-		// 解析 Token
-		claims, err := utils.ParseToken(parts[1])
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication failed, please try again.", nil)
-			c.Abort()
-			return
-		}
+	parts := strings.Split(tokenString, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, &authError{http.StatusUnauthorized, "Authentication failed, please try again."}
+	}
 
-		perms, err := models.GetUserWithPermissions(claims.Uid)
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized action", nil)
-			c.Abort()
-			return
-		}
+	// 解析 Token
+	claims, err := utils.ParseToken(parts[1])
+	if err != nil {
+		return nil, &authError{http.StatusUnauthorized, "Authentication failed, please try again."}
+	}
+
+	// 已主动吊销的 token
+	revoked, err := models.IsAccessTokenRevoked(c.Request.Context(), claims.ID)
+	if err != nil || revoked {
+		return nil, &authError{http.StatusUnauthorized, "Authentication failed, please try again."}
+	}
+
+	perms, tokenVersion, err := models.GetUserAuthState(c.Request.Context(), claims.Uid)
+	if err != nil {
+		return nil, &authError{http.StatusUnauthorized, "Unauthorized action"}
+	}
+
+	// 退出所有设备后旧 token 失效
+	if claims.TokenVersion != tokenVersion {
+		return nil, &authError{http.StatusUnauthorized, "Please log in to continue!"}
+	}
 
-		if isEqual := utils.StringSlicesEqual(perms, claims.Permissions); !isEqual {
-			utils.ErrorResponse(c, http.StatusForbidden, " permission change", nil)
+	if isEqual := utils.StringSlicesEqual(perms, claims.Permissions); !isEqual {
+		return nil, &authError{http.StatusForbidden, " permission change"}
+	}
+	return claims, nil
+}
+
+func setClaims(c *gin.Context, claims *utils.Claims) {
+	c.Set("uid", claims.Uid)
+	c.Set("permissions", claims.Permissions)
+	c.Set("jti", claims.ID)
+	if claims.ExpiresAt != nil {
+		c.Set("exp", claims.ExpiresAt.Time)
+	}
+}
+
+func JWT(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, authErr := authenticate(c)
+		if authErr != nil {
+			utils.ErrorResponse(c, authErr.status, authErr.message, nil)
 			c.Abort()
 			return
 		}
@@ -57,8 +85,19 @@ func JWT(permission string) gin.HandlerFunc {
 			}
 		}
 
-		c.Set("uid", claims.Uid)
-		c.Set("permissions", claims.Permissions)
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// OptionalJWT 携带的 token 通过与 JWT 相同的校验时设置 uid 和 permissions，
+// 没有 token 或校验失败（包括权限已变更的旧 token）时按匿名用户继续处理，
+// 用于匿名可访问、登录后返回个性化内容的接口
+func OptionalJWT() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, authErr := authenticate(c); authErr == nil {
+			setClaims(c, claims)
+		}
 		c.Next()
 	}
 }
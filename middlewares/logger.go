@@ -5,19 +5,51 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
+// LoggerMiddleware 访问日志，每个请求输出一条结构化记录，需注册在 RequestID 之后
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		c.Next()
 		duration := time.Since(start)
 
-		logger.Log.Infof("%s %s %d %s",
-			c.Request.Method,
-			c.Request.URL.Path,
-			c.Writer.Status(),
-			duration,
-		)
+		// 使用路由模板而不是实际路径，便于按接口聚合；未匹配路由时为空
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		bytes := c.Writer.Size()
+		if bytes < 0 {
+			bytes = 0
+		}
+
+		entry := logger.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"route":      route,
+			"path":       c.Request.URL.Path,
+			"status":     c.Writer.Status(),
+			"latency_ms": float64(duration.Microseconds()) / 1000,
+			"bytes":      bytes,
+			"ip":         c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		})
+		if uid := c.GetUint("uid"); uid != 0 {
+			entry = entry.WithField("uid", uid)
+		}
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("errors", c.Errors.String())
+		}
+
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			entry.Error("request")
+		case status >= 400:
+			entry.Warn("request")
+		default:
+			entry.Info("request")
+		}
 	}
 }
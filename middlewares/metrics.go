@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"hyperlane/logger"
+	"hyperlane/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsAuth 限制 /metrics 的访问：携带 Authorization: Bearer <token> 且与 token 一致，
+// 或客户端 IP 落在 allowed（IP 或 CIDR）内时放行；两者都未配置时拒绝所有请求。
+// 客户端 IP 取 c.ClientIP()，经过反向代理时需要配置 server.trustedProxies
+func MetricsAuth(token string, allowed []string) gin.HandlerFunc {
+	var prefixes []netip.Prefix
+	for _, s := range allowed {
+		prefix, err := parsePrefix(s)
+		if err != nil {
+			logger.Log.Warnf("metrics.allowedIPs: ignore invalid entry %q: %v", s, err)
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return func(c *gin.Context) {
+		if token != "" {
+			if got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok &&
+				subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				c.Next()
+				return
+			}
+		}
+		if ip, err := netip.ParseAddr(c.ClientIP()); err == nil {
+			ip = ip.Unmap()
+			for _, prefix := range prefixes {
+				if prefix.Contains(ip) {
+					c.Next()
+					return
+				}
+			}
+		}
+		utils.ErrorResponse(c, http.StatusForbidden, "forbidden", nil)
+		c.Abort()
+	}
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
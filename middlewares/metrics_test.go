@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		token      string
+		allowed    []string
+		remoteAddr string
+		auth       string
+		want       int
+	}{
+		{name: "Nothing configured", remoteAddr: "127.0.0.1:5000", want: http.StatusForbidden},
+		{name: "Loopback allowed", allowed: []string{"127.0.0.1", "::1"}, remoteAddr: "127.0.0.1:5000", want: http.StatusOK},
+		{name: "IPv6 loopback allowed", allowed: []string{"127.0.0.1", "::1"}, remoteAddr: "[::1]:5000", want: http.StatusOK},
+		{name: "CIDR allowed", allowed: []string{"10.0.0.0/8"}, remoteAddr: "10.1.2.3:5000", want: http.StatusOK},
+		{name: "Outside allowlist", allowed: []string{"10.0.0.0/8"}, remoteAddr: "203.0.113.7:5000", want: http.StatusForbidden},
+		{name: "Invalid entry ignored", allowed: []string{"not-an-ip"}, remoteAddr: "203.0.113.7:5000", want: http.StatusForbidden},
+		{name: "Valid token", token: "s3cret", remoteAddr: "203.0.113.7:5000", auth: "Bearer s3cret", want: http.StatusOK},
+		{name: "Wrong token", token: "s3cret", remoteAddr: "203.0.113.7:5000", auth: "Bearer guess", want: http.StatusForbidden},
+		{name: "Empty token never matches", remoteAddr: "203.0.113.7:5000", auth: "Bearer ", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/metrics", MetricsAuth(tt.token, tt.allowed), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
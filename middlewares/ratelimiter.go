@@ -1,17 +1,108 @@
 package middlewares
 
 import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"hyperlane/logger"
+	"hyperlane/ratelimit"
+	"hyperlane/utils"
+
 	"github.com/gin-gonic/gin"
-	"go.uber.org/ratelimit"
 )
 
-// LeakyBucketRateLimiter 创建一个基于漏桶算法的限流中间件
-func LeakyBucketRateLimiter(rate int) gin.HandlerFunc {
-	// 每秒可以处理的请求数量
-	rl := ratelimit.New(rate) // 每秒 rate 个请求
-
+// RateLimit 令牌桶限流：登录用户按 uid 计数，匿名用户按 IP 计数；
+// 超出时返回 429 和 Retry-After，存储不可用时放行
+func RateLimit(store ratelimit.Store, rules *ratelimit.Rules) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		rl.Take() // 等待直到有足够的空间进行处理
+		if c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		rule := rules.Match(c.Request.Method, c.FullPath())
+		policy := rule.Policy()
+		if !policy.Valid() {
+			c.Next()
+			return
+		}
+
+		key := rule.Name + ":" + rateLimitIdentity(c)
+		res, err := store.Take(c.Request.Context(), key, policy)
+		if err != nil {
+			logger.WithContext(c.Request.Context()).Errorf("rate limit store: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "too many requests, please slow down", nil)
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// 限流在 JWT 中间件之前执行，这里只解析 token 取 uid，吊销检查仍由 JWT 中间件负责
+func rateLimitIdentity(c *gin.Context) string {
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		if claims, err := utils.ParseToken(token); err == nil && claims.Uid != 0 {
+			return fmt.Sprintf("uid:%d", claims.Uid)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// 已配置的可信代理，FromTrustedProxy 据此判断是否采用 X-Forwarded-* 头
+var trustedProxies []netip.Prefix
+
+// TrustProxies 只信任这些代理（IP 或 CIDR）写入的 X-Forwarded-For；为空时 ClientIP 直接取连接地址，
+// 否则客户端可以伪造 X-Forwarded-For 绕过按 IP 的限流
+func TrustProxies(r *gin.Engine, proxies []string) error {
+	if len(proxies) == 0 {
+		proxies = nil
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		return fmt.Errorf("server.trustedProxies: %w", err)
+	}
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, s := range proxies {
+		prefix, err := parsePrefix(s)
+		if err != nil {
+			return fmt.Errorf("server.trustedProxies: %w", err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	trustedProxies = prefixes
+	return nil
+}
+
+// FromTrustedProxy 请求的直接来源是否为 server.trustedProxies 中的代理，
+// 只有这时 X-Forwarded-Proto 等代理写入的头才可信
+func FromTrustedProxy(c *gin.Context) bool {
+	ip, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
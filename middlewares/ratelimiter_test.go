@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hyperlane/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rules := &ratelimit.Rules{Default: ratelimit.Rule{Name: "default", Limit: 2, Period: time.Hour}}
+
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		forwarded  []string // 每次请求携带的 X-Forwarded-For
+		want       []int
+	}{
+		{
+			name:       "Spoofed header without trusted proxy",
+			remoteAddr: "203.0.113.7:5000",
+			forwarded:  []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+			want:       []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:       "Spoofed header from untrusted peer",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "203.0.113.7:5000",
+			forwarded:  []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+			want:       []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:       "Clients behind trusted proxy",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+			want:       []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := TrustProxies(r, tt.proxies); err != nil {
+				t.Fatal(err)
+			}
+			r.Use(RateLimit(ratelimit.NewMemory(), rules))
+			r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			for i, xff := range tt.forwarded {
+				req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+				req.RemoteAddr = tt.remoteAddr
+				req.Header.Set("X-Forwarded-For", xff)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != tt.want[i] {
+					t.Errorf("request %d status = %d, want %d", i+1, w.Code, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFromTrustedProxy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { trustedProxies = nil })
+
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		want       bool
+	}{
+		{name: "No trusted proxies", remoteAddr: "10.0.0.2:5000", want: false},
+		{name: "Untrusted peer", proxies: []string{"10.0.0.0/8"}, remoteAddr: "203.0.113.7:5000", want: false},
+		{name: "Trusted CIDR", proxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:5000", want: true},
+		{name: "Trusted IP", proxies: []string{"192.0.2.1"}, remoteAddr: "192.0.2.1:5000", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := TrustProxies(r, tt.proxies); err != nil {
+				t.Fatal(err)
+			}
+			var got bool
+			r.GET("/ping", func(c *gin.Context) {
+				got = FromTrustedProxy(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.RemoteAddr = tt.remoteAddr
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("FromTrustedProxy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
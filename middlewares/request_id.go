@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"hyperlane/logger"
+	"hyperlane/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// 只接受长度合理的可打印标识，防止日志注入
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID 沿用上游传入的 X-Request-ID，没有时生成一个；写入响应头、gin context 和 request context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = utils.GenerateRequestId()
+		}
+
+		c.Set("request_id", id)
+		c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name: "Sorted by version",
+			files: fstest.MapFS{
+				"sql/0002_b.up.sql":   {Data: []byte("b")},
+				"sql/0001_a.up.sql":   {Data: []byte("a")},
+				"sql/0001_a.down.sql": {Data: []byte("-a")},
+			},
+			versions: []int64{1, 2},
+			wantErr:  false,
+		},
+		{
+			name: "Invalid file name",
+			files: fstest.MapFS{
+				"sql/init.sql": {Data: []byte("a")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Duplicate version",
+			files: fstest.MapFS{
+				"sql/0001_a.up.sql": {Data: []byte("a")},
+				"sql/0001_b.up.sql": {Data: []byte("b")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing up file",
+			files: fstest.MapFS{
+				"sql/0001_a.down.sql": {Data: []byte("-a")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := load(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(list) != len(tt.versions) {
+				t.Fatalf("load() returned %d migrations, want %d", len(list), len(tt.versions))
+			}
+			for i, v := range tt.versions {
+				if list[i].Version != v {
+					t.Errorf("migration %d version = %d, want %d", i, list[i].Version, v)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, m := range list {
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+DROP TABLE IF EXISTS
+    revoked_tokens,
+    refresh_tokens,
+    audit_logs,
+    notifications,
+    job_runs,
+    event_registrations,
+    comments,
+    dapps,
+    follows,
+    daily_stats,
+    post_favorites,
+    post_likes,
+    posts,
+    feedbacks,
+    articles,
+    recaps,
+    events,
+    users,
+    role_permission_groups,
+    role_permissions,
+    roles,
+    permission_group_permissions,
+    permission_groups,
+    permissions
+CASCADE;
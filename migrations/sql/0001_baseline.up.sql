@@ -0,0 +1,390 @@
+-- 基线：AutoMigrate 时代的表结构加上此后新增的表和列，全部使用 IF NOT EXISTS，
+-- 已有数据库可直接执行，缺少的列会被补齐
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text,
+    description text
+);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permission_groups (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text,
+    description text
+);
+CREATE INDEX IF NOT EXISTS idx_permission_groups_deleted_at ON permission_groups (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permission_group_permissions (
+    permission_group_id bigint NOT NULL,
+    permission_id bigint NOT NULL,
+    PRIMARY KEY (permission_group_id, permission_id),
+    CONSTRAINT fk_permission_group_permissions_permission_group FOREIGN KEY (permission_group_id) REFERENCES permission_groups(id),
+    CONSTRAINT fk_permission_group_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions(id)
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text,
+    description text
+);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id bigint NOT NULL,
+    permission_id bigint NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles(id),
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions(id)
+);
+
+CREATE TABLE IF NOT EXISTS role_permission_groups (
+    role_id bigint NOT NULL,
+    permission_group_id bigint NOT NULL,
+    PRIMARY KEY (role_id, permission_group_id),
+    CONSTRAINT fk_role_permission_groups_role FOREIGN KEY (role_id) REFERENCES roles(id),
+    CONSTRAINT fk_role_permission_groups_permission_group FOREIGN KEY (permission_group_id) REFERENCES permission_groups(id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    email text NOT NULL,
+    CONSTRAINT uni_users_email UNIQUE (email),
+    username text,
+    avatar text,
+    github text,
+    twitter text,
+    uid bigint,
+    role_id bigint,
+    CONSTRAINT fk_users_role FOREIGN KEY (role_id) REFERENCES roles(id)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS events (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title text,
+    description text,
+    event_mode text,
+    event_type text,
+    location text,
+    link text,
+    registration_deadline timestamptz,
+    registration_link text,
+    start_time timestamptz,
+    end_time timestamptz,
+    cover_img text,
+    tags text[],
+    participants bigint,
+    status bigint DEFAULT 0,
+    publish_status bigint DEFAULT 1,
+    publish_time timestamptz,
+    twitter text,
+    user_id bigint,
+    CONSTRAINT fk_users_events FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_events_deleted_at ON events (deleted_at);
+
+CREATE TABLE IF NOT EXISTS recaps (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    content text,
+    video text,
+    recording text,
+    twitter text,
+    event_id bigint,
+    user_id bigint,
+    CONSTRAINT fk_recaps_event FOREIGN KEY (event_id) REFERENCES events(id),
+    CONSTRAINT fk_recaps_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_recaps_deleted_at ON recaps (deleted_at);
+
+CREATE TABLE IF NOT EXISTS articles (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title text,
+    description text,
+    content text,
+    source_link text,
+    source_type text,
+    cover_img text,
+    tags text[],
+    category text,
+    author text,
+    translator text,
+    publisher_id bigint,
+    publish_time timestamptz,
+    publish_status bigint DEFAULT 1,
+    view_count bigint DEFAULT 0,
+    CONSTRAINT fk_users_articles FOREIGN KEY (publisher_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_articles_deleted_at ON articles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS feedbacks (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    content text,
+    url text,
+    email text,
+    user_id bigint,
+    CONSTRAINT fk_feedbacks_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_feedbacks_deleted_at ON feedbacks (deleted_at);
+
+CREATE TABLE IF NOT EXISTS posts (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title text,
+    description text,
+    twitter text,
+    tags text[],
+    view_count bigint,
+    user_id bigint,
+    like_count bigint,
+    favorite_count bigint,
+    CONSTRAINT fk_users_posts FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS post_likes (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    post_id bigint NOT NULL,
+    user_id bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_post_likes_deleted_at ON post_likes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_post ON post_likes (post_id, user_id);
+
+CREATE TABLE IF NOT EXISTS post_favorites (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    post_id bigint NOT NULL,
+    user_id bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_post_favorites_deleted_at ON post_favorites (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_post_favorite ON post_favorites (post_id, user_id);
+
+CREATE TABLE IF NOT EXISTS daily_stats (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    date timestamptz,
+    users bigint,
+    blogs bigint,
+    events bigint,
+    posts bigint
+);
+CREATE INDEX IF NOT EXISTS idx_daily_stats_deleted_at ON daily_stats (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_stats_date ON daily_stats (date);
+
+CREATE TABLE IF NOT EXISTS follows (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    follower_id bigint NOT NULL,
+    following_id bigint NOT NULL,
+    CONSTRAINT fk_follows_follower FOREIGN KEY (follower_id) REFERENCES users(id),
+    CONSTRAINT fk_follows_following FOREIGN KEY (following_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_follows_deleted_at ON follows (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_follows_follower_id ON follows (follower_id);
+CREATE INDEX IF NOT EXISTS idx_follows_following_id ON follows (following_id);
+
+-- 以上为 AutoMigrate 时代的表结构；之后在这些表上新增的列用 ADD COLUMN IF NOT EXISTS 补齐，
+-- 已有数据库执行时同样会创建
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version bigint DEFAULT 0;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS capacity bigint DEFAULT 0;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS comment_count bigint DEFAULT 0;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS comment_count bigint DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_count bigint DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS dapps (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text,
+    description text,
+    chain text,
+    category text,
+    website text,
+    contracts text[],
+    logo text,
+    tags text[],
+    twitter text,
+    publisher_id bigint,
+    publish_time timestamptz,
+    publish_status bigint DEFAULT 1,
+    CONSTRAINT fk_dapps_publisher FOREIGN KEY (publisher_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_dapps_deleted_at ON dapps (deleted_at);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    target_type text NOT NULL,
+    target_id bigint NOT NULL,
+    root_id bigint,
+    parent_id bigint,
+    content text,
+    user_id bigint,
+    CONSTRAINT fk_comments_replies FOREIGN KEY (root_id) REFERENCES comments(id),
+    CONSTRAINT fk_comments_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_comment_target ON comments (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_comments_root_id ON comments (root_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+
+CREATE TABLE IF NOT EXISTS event_registrations (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    event_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    name text,
+    email text,
+    status bigint,
+    registered_at timestamptz,
+    CONSTRAINT fk_event_registrations_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_event_registrations_deleted_at ON event_registrations (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_user ON event_registrations (event_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_event_registrations_status ON event_registrations (status);
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text NOT NULL,
+    scheduled_at timestamptz NOT NULL,
+    started_at timestamptz,
+    finished_at timestamptz,
+    duration_ms bigint,
+    status text,
+    error text,
+    host text
+);
+CREATE INDEX IF NOT EXISTS idx_job_runs_deleted_at ON job_runs (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_job_slot ON job_runs (name, scheduled_at);
+CREATE INDEX IF NOT EXISTS idx_job_runs_status ON job_runs (status);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint NOT NULL,
+    actor_id bigint,
+    type text,
+    target_type text,
+    target_id bigint,
+    content text,
+    read_at timestamptz,
+    CONSTRAINT fk_notifications_actor FOREIGN KEY (actor_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_notification_user ON notifications (user_id, read_at);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    actor_id bigint,
+    action text,
+    target_type text,
+    target_id bigint,
+    detail text,
+    CONSTRAINT fk_audit_logs_actor FOREIGN KEY (actor_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_deleted_at ON audit_logs (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_target ON audit_logs (target_type, target_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint NOT NULL,
+    family_id text NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz,
+    used_at timestamptz,
+    revoked_at timestamptz,
+    user_agent text,
+    ip text
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    jti text NOT NULL,
+    user_id bigint,
+    expires_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_deleted_at ON revoked_tokens (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revoked_tokens_jti ON revoked_tokens (jti);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_id ON revoked_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+-- 全文检索：tsvector 生成列及 GIN 索引，使用 simple 配置；search.config 不同时由 models.InitSearch 重建该列
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_posts_search ON posts USING GIN (search_vector);
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(content, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_articles_search ON articles USING GIN (search_vector);
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(location, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_events_search ON events USING GIN (search_vector);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_title_trgm;
+DROP INDEX IF EXISTS idx_articles_title_trgm;
+DROP INDEX IF EXISTS idx_events_title_trgm;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS uploads (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint NOT NULL,
+    hash text NOT NULL,
+    key text NOT NULL,
+    thumb_key text,
+    url text NOT NULL,
+    thumb_url text,
+    mime_type text,
+    size bigint,
+    width bigint,
+    height bigint
+);
+CREATE INDEX IF NOT EXISTS idx_uploads_deleted_at ON uploads (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_upload_owner_hash ON uploads (user_id, hash);
+CREATE INDEX IF NOT EXISTS idx_uploads_hash ON uploads (hash);
+CREATE INDEX IF NOT EXISTS idx_uploads_created_at ON uploads (created_at);
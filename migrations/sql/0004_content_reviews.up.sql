@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS content_reviews (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    target_type text NOT NULL,
+    target_id bigint NOT NULL,
+    reviewer_id bigint,
+    action text,
+    from_status bigint,
+    to_status bigint,
+    note text,
+    CONSTRAINT fk_content_reviews_reviewer FOREIGN KEY (reviewer_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_content_reviews_deleted_at ON content_reviews (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_review_target ON content_reviews (target_type, target_id);
@@ -0,0 +1,3 @@
+ALTER TABLE articles DROP COLUMN IF EXISTS pending_revision_id;
+ALTER TABLE articles DROP COLUMN IF EXISTS revision_id;
+DROP TABLE IF EXISTS article_revisions;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS article_revisions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    article_id bigint NOT NULL,
+    version bigint NOT NULL,
+    title text,
+    description text,
+    content text,
+    source_link text,
+    source_type text,
+    cover_img text,
+    tags text[],
+    category text,
+    author text,
+    translator text,
+    editor_id bigint,
+    status text,
+    restored_from bigint,
+    CONSTRAINT fk_article_revisions_editor FOREIGN KEY (editor_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_article_revisions_deleted_at ON article_revisions (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_article_version ON article_revisions (article_id, version);
+CREATE INDEX IF NOT EXISTS idx_article_revisions_status ON article_revisions (status);
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS revision_id bigint;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS pending_revision_id bigint;
+
+-- 已有博客以当前内容作为第 1 个修订
+INSERT INTO article_revisions (created_at, updated_at, article_id, version, title, description, content,
+    source_link, source_type, cover_img, tags, category, author, translator, editor_id, status)
+SELECT coalesce(a.updated_at, now()), coalesce(a.updated_at, now()), a.id, 1, a.title, a.description, a.content,
+    a.source_link, a.source_type, a.cover_img, a.tags, a.category, a.author, a.translator, NULLIF(a.publisher_id, 0), 'applied'
+FROM articles a
+WHERE NOT EXISTS (SELECT 1 FROM article_revisions r WHERE r.article_id = a.id);
+
+UPDATE articles a SET revision_id = r.id
+FROM article_revisions r
+WHERE r.article_id = a.id AND r.version = 1 AND a.revision_id IS NULL;
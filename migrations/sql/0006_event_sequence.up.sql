@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS sequence bigint DEFAULT 0;
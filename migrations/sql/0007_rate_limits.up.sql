@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key text PRIMARY KEY,
+    tokens double precision NOT NULL,
+    allowed boolean NOT NULL DEFAULT true,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_rate_limits_updated_at ON rate_limits (updated_at);
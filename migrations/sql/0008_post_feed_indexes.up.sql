@@ -0,0 +1,4 @@
+-- 帖子列表游标分页和信息流候选按发布时间筛选
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts (created_at, id);
+-- 关注流按作者筛选
+CREATE INDEX IF NOT EXISTS idx_posts_user_id_created_at ON posts (user_id, created_at);
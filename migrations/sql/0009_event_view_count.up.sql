@@ -0,0 +1,2 @@
+-- 活动详情浏览量，由 views 包批量写入
+ALTER TABLE events ADD COLUMN IF NOT EXISTS view_count bigint DEFAULT 0;
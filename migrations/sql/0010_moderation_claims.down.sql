@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_content_reviews_created_at;
+DROP TABLE IF EXISTS moderation_claims;
@@ -0,0 +1,12 @@
+-- 审核认领，每条待审核内容同一时间最多一个认领
+CREATE TABLE IF NOT EXISTS moderation_claims (
+    target_type text NOT NULL,
+    target_id bigint NOT NULL,
+    reviewer_id bigint NOT NULL,
+    claimed_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL,
+    PRIMARY KEY (target_type, target_id),
+    CONSTRAINT fk_moderation_claims_reviewer FOREIGN KEY (reviewer_id) REFERENCES users(id)
+);
+-- 审核时效统计按时间窗口筛选
+CREATE INDEX IF NOT EXISTS idx_content_reviews_created_at ON content_reviews (created_at);
@@ -0,0 +1,14 @@
+-- OAuth 回调签发的一次性登录码，前端 POST /v1/auth/exchange 换取令牌
+CREATE TABLE IF NOT EXISTS login_codes (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    code_hash text NOT NULL,
+    user_id bigint NOT NULL,
+    expires_at timestamptz,
+    used_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_login_codes_deleted_at ON login_codes (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_login_codes_code_hash ON login_codes (code_hash);
+CREATE INDEX IF NOT EXISTS idx_login_codes_expires_at ON login_codes (expires_at);
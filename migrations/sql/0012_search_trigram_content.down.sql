@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_posts_description_trgm;
+DROP INDEX IF EXISTS idx_articles_description_trgm;
+DROP INDEX IF EXISTS idx_articles_content_trgm;
+DROP INDEX IF EXISTS idx_events_description_trgm;
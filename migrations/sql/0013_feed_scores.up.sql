@@ -0,0 +1,9 @@
+-- 信息流热度快照：定时任务按当时的浏览、点赞、收藏数为帖子计算热度分，
+-- 翻页时固定使用第一页所在的快照，互动量变化不会让帖子在页与页之间移动
+CREATE TABLE IF NOT EXISTS feed_scores (
+    snapshot_at timestamptz NOT NULL,
+    post_id bigint NOT NULL,
+    score double precision NOT NULL,
+    PRIMARY KEY (snapshot_at, post_id)
+);
+CREATE INDEX IF NOT EXISTS idx_feed_scores_rank ON feed_scores (snapshot_at, score DESC, post_id DESC);
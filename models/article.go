@@ -1,7 +1,9 @@
 package models
 
 import (
+	"context"
 	"errors"
+	"hyperlane/utils"
 	"time"
 
 	"github.com/lib/pq"
@@ -10,49 +12,67 @@ import (
 
 type Article struct {
 	gorm.Model
-	Title         string         `json:"title"`
-	Description   string         `json:"description"`
-	Content       string         `gorm:"type:text" json:"content"`
-	SourceLink    string         `json:"source_link"`
-	SourceType    string         `json:"source_type"`
-	CoverImg      string         `json:"cover_img"`
-	Tags          pq.StringArray `gorm:"type:text[]" json:"tags"`
-	Category      string         `json:"category"`
-	Author        string         `json:"author"`
-	Translator    string         `json:"translator"`
-	PublisherId   uint           `json:"publisher_id"`
-	Publisher     *User          `gorm:"foreignKey:PublisherId" json:"publisher"`
-	PublishTime   *time.Time     `json:"publish_time"`
-	PublishStatus uint           `gorm:"default:1" json:"publish_status"` // 0:全部 1:待审核 2:已发布
-	ViewCount     uint           `gorm:"default:0" json:"view_count"`
+	Title             string         `json:"title"`
+	Description       string         `json:"description"`
+	Content           string         `gorm:"type:text" json:"content"`
+	SourceLink        string         `json:"source_link"`
+	SourceType        string         `json:"source_type"`
+	CoverImg          string         `json:"cover_img"`
+	Tags              pq.StringArray `gorm:"type:text[]" json:"tags"`
+	Category          string         `json:"category"`
+	Author            string         `json:"author"`
+	Translator        string         `json:"translator"`
+	PublisherId       uint           `json:"publisher_id"`
+	Publisher         *User          `gorm:"foreignKey:PublisherId" json:"publisher"`
+	PublishTime       *time.Time     `json:"publish_time"`
+	PublishStatus     uint           `gorm:"default:1" json:"publish_status"` // 1:待审核 2:已发布 3:草稿 4:退回修改 5:审核通过 6:已下线 7:已归档
+	ViewCount         uint           `gorm:"default:0" json:"view_count"`
+	CommentCount      uint           `gorm:"default:0" json:"comment_count"`
+	RevisionId        *uint          `json:"revision_id"`         // 当前线上内容对应的修订
+	PendingRevisionId *uint          `json:"pending_revision_id"` // 已发布博客等待审核的修订
 }
 
-func (a *Article) Create() error {
-	return db.Create(a).Error
+// Create 创建博客并生成第 1 个修订
+func (a *Article) Create(ctx context.Context) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		rev, err := createRevision(tx, a.ID, a.PublisherId, a.content(), RevisionApplied)
+		if err != nil {
+			return err
+		}
+		a.RevisionId = &rev.ID
+		return tx.Model(a).Update("revision_id", rev.ID).Error
+	})
 }
 
-func (a *Article) GetByID(id uint) error {
-	if err := db.Preload("Publisher").First(a, id).Error; err != nil {
+func (a *Article) GetByID(ctx context.Context, id uint) error {
+	if err := db.WithContext(ctx).Preload("Publisher").First(a, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
 		return err
 	}
-
-	// 更新浏览量（+1）
-	// TODO: handle in controller
-	return db.Model(a).Update("view_count", gorm.Expr("view_count + ?", 1)).Error
+	return nil
 }
 
-func (a *Article) Update() error {
+// 由浏览量刷新、评论维护的计数列，编辑时不覆盖
+var articleCounterColumns = []string{"view_count", "comment_count"}
+
+// Update 保存博客，计数列以数据库为准
+func (a *Article) Update(ctx context.Context) error {
 	if a.ID == 0 {
 		return errors.New("missing Article ID")
 	}
-	return db.Save(a).Error
+	return db.WithContext(ctx).Omit(articleCounterColumns...).Save(a).Error
 }
 
-func (a *Article) Delete() error {
+func (a *Article) Delete(ctx context.Context) error {
 	if a.ID == 0 {
 		return errors.New("missing Article ID")
 	}
-	return db.Delete(a).Error
+	return db.WithContext(ctx).Delete(a).Error
 }
 
 type ArticleFilter struct {
@@ -63,15 +83,25 @@ type ArticleFilter struct {
 	OrderDesc     bool   // 是否按发布时间排序
 	PublishStatus int    // 发布状态
 	PublisherId   int
-	Page          int // 当前页码，从 1 开始
-	PageSize      int // 每页数量，建议默认 10
+	Page          int           // 当前页码，从 1 开始
+	PageSize      int           // 每页数量，建议默认 10
+	Cursor        *utils.Cursor // 游标分页，非空时忽略 Page 和 OrderDesc
+	SkipTotal     bool          // 不统计总数，total 返回 -1
 }
 
-func QueryArticles(filter ArticleFilter) ([]Article, int64, error) {
+// CursorKey 游标按发布时间排序，未发布的博客使用创建时间
+func (a Article) CursorKey() (time.Time, uint) {
+	if a.PublishTime != nil {
+		return *a.PublishTime, a.ID
+	}
+	return a.CreatedAt, a.ID
+}
+
+func QueryArticles(ctx context.Context, filter ArticleFilter) ([]Article, int64, error) {
 	var articles []Article
 	var total int64
 
-	query := db.Preload("Publisher").Model(&Article{})
+	query := db.WithContext(ctx).Preload("Publisher").Model(&Article{})
 
 	if filter.Keyword != "" {
 		likePattern := "%" + filter.Keyword + "%"
@@ -95,7 +125,18 @@ func QueryArticles(filter ArticleFilter) ([]Article, int64, error) {
 	}
 
 	// 统计总数（不加 limit 和 offset）
-	query.Count(&total)
+	total = -1
+	if !filter.SkipTotal {
+		query.Count(&total)
+	}
+
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	if filter.Cursor != nil {
+		err := keysetPage(query, "COALESCE(publish_time, created_at)", "id", filter.Cursor, filter.PageSize).Find(&articles).Error
+		return articles, total, err
+	}
 
 	// 排序
 	if filter.OrderDesc {
@@ -108,9 +149,6 @@ func QueryArticles(filter ArticleFilter) ([]Article, int64, error) {
 	if filter.Page < 1 {
 		filter.Page = 1
 	}
-	if filter.PageSize <= 0 {
-		filter.PageSize = 10
-	}
 	offset := (filter.Page - 1) * filter.PageSize
 	query = query.Offset(offset).Limit(filter.PageSize)
 
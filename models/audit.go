@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// 后台管理操作审计日志
+type AuditLog struct {
+	gorm.Model
+	ActorId    uint   `gorm:"index" json:"actor_id"`
+	Actor      *User  `gorm:"foreignKey:ActorId" json:"actor"`
+	Action     string `gorm:"index" json:"action"`                       // 如 role.create、role.permission.attach
+	TargetType string `gorm:"index:idx_audit_target" json:"target_type"` // role / permission_group / user
+	TargetId   uint   `gorm:"index:idx_audit_target" json:"target_id"`
+	Detail     string `gorm:"type:text" json:"detail"` // 变更内容（JSON）
+}
+
+func writeAuditLog(tx *gorm.DB, actorId uint, action, targetType string, targetId uint, detail interface{}) error {
+	var raw []byte
+	if detail != nil {
+		var err error
+		if raw, err = json.Marshal(detail); err != nil {
+			return err
+		}
+	}
+
+	return tx.Create(&AuditLog{
+		ActorId:    actorId,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		Detail:     string(raw),
+	}).Error
+}
+
+type AuditLogFilter struct {
+	ActorId    uint
+	TargetType string
+	TargetId   uint
+	Page       int // 当前页码，从 1 开始
+	PageSize   int // 每页数量，建议默认 10
+}
+
+func QueryAuditLogs(ctx context.Context, filter AuditLogFilter) ([]AuditLog, int64, error) {
+	var logs []AuditLog
+	var total int64
+
+	query := db.WithContext(ctx).Preload("Actor").Model(&AuditLog{})
+
+	if filter.ActorId != 0 {
+		query = query.Where("actor_id = ?", filter.ActorId)
+	}
+
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+
+	if filter.TargetId != 0 {
+		query = query.Where("target_id = ?", filter.TargetId)
+	}
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	query = query.Order("created_at desc")
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.Offset(offset).Limit(filter.PageSize)
+
+	err := query.Find(&logs).Error
+	return logs, total, err
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"hyperlane/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCommentTarget  = utils.NewAppError(http.StatusBadRequest, "INVALID_COMMENT_TARGET", "invalid comment target")
+	ErrParentCommentNotFound = utils.NewAppError(http.StatusNotFound, "PARENT_COMMENT_NOT_FOUND", "parent comment not found")
+)
+
+const (
+	CommentTargetPost  = "post"
+	CommentTargetBlog  = "blog"
+	CommentTargetEvent = "event"
+)
+
+type Comment struct {
+	gorm.Model
+	TargetType string     `gorm:"index:idx_comment_target;not null" json:"target_type"` // post / blog / event
+	TargetId   uint       `gorm:"index:idx_comment_target;not null" json:"target_id"`
+	RootId     *uint      `gorm:"index" json:"root_id"`   // 所属楼层（顶层评论 ID），顶层评论为空
+	ParentId   *uint      `gorm:"index" json:"parent_id"` // 回复的评论 ID
+	Content    string     `gorm:"type:text" json:"content"`
+	UserId     uint       `json:"user_id"`
+	User       *User      `gorm:"foreignKey:UserId" json:"user"`
+	Replies    []*Comment `gorm:"foreignKey:RootId" json:"replies,omitempty"`
+}
+
+// 评论目标类型对应的模型，用于校验目标是否存在以及维护 comment_count
+func commentTargetModel(targetType string) (interface{}, error) {
+	switch targetType {
+	case CommentTargetPost:
+		return &Post{}, nil
+	case CommentTargetBlog:
+		return &Article{}, nil
+	case CommentTargetEvent:
+		return &Event{}, nil
+	}
+	return nil, ErrInvalidCommentTarget
+}
+
+// 检查评论目标是否存在
+func CommentTargetExists(ctx context.Context, targetType string, targetId uint) (bool, error) {
+	model, err := commentTargetModel(targetType)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := db.WithContext(ctx).Model(model).Where("id = ?", targetId).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (c *Comment) GetByID(ctx context.Context, id uint) error {
+	return db.WithContext(ctx).Preload("User").First(c, id).Error
+}
+
+func (c *Comment) Update(ctx context.Context) error {
+	if c.ID == 0 {
+		return errors.New("missing comment ID")
+	}
+	return db.WithContext(ctx).Model(c).Update("content", c.Content).Error
+}
+
+// 发表评论，同时维护目标的 comment_count
+func (c *Comment) Create(ctx context.Context) error {
+	model, err := commentTargetModel(c.TargetType)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if c.ParentId != nil {
+			var parent Comment
+			err := tx.First(&parent, *c.ParentId).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrParentCommentNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if parent.TargetType != c.TargetType || parent.TargetId != c.TargetId {
+				return ErrParentCommentNotFound
+			}
+
+			// 回复统一挂在顶层评论下
+			rootId := parent.ID
+			if parent.RootId != nil {
+				rootId = *parent.RootId
+			}
+			c.RootId = &rootId
+		}
+
+		if err := tx.Create(c).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(model).
+			Where("id = ?", c.TargetId).
+			UpdateColumn("comment_count", gorm.Expr("comment_count + ?", 1)).Error
+	})
+}
+
+// 删除评论（软删除），顶层评论会连同其回复一起删除
+func (c *Comment) Delete(ctx context.Context) error {
+	if c.ID == 0 {
+		return errors.New("missing comment ID")
+	}
+
+	model, err := commentTargetModel(c.TargetType)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? OR root_id = ?", c.ID, c.ID).Delete(&Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		return tx.Model(model).
+			Where("id = ?", c.TargetId).
+			UpdateColumn("comment_count", gorm.Expr("GREATEST(comment_count - ?, 0)", res.RowsAffected)).Error
+	})
+}
+
+type CommentFilter struct {
+	TargetType string
+	TargetId   uint
+	OrderDesc  bool // 是否按创建时间倒序
+	Page       int  // 当前页码，从 1 开始
+	PageSize   int  // 每页数量，建议默认 10
+}
+
+// 查询顶层评论（分页），并带出每条评论下的回复
+func QueryComments(ctx context.Context, filter CommentFilter) ([]Comment, int64, error) {
+	var comments []Comment
+	var total int64
+
+	query := db.WithContext(ctx).Preload("User").
+		Preload("Replies", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("created_at asc")
+		}).
+		Preload("Replies.User").
+		Model(&Comment{}).
+		Where("target_type = ? AND target_id = ? AND root_id IS NULL", filter.TargetType, filter.TargetId)
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	// 排序
+	if filter.OrderDesc {
+		query = query.Order("created_at desc")
+	} else {
+		query = query.Order("created_at asc")
+	}
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.Offset(offset).Limit(filter.PageSize)
+
+	err := query.Find(&comments).Error
+	return comments, total, err
+}
@@ -1 +1,43 @@
 package models
+
+import (
+	"fmt"
+	"hyperlane/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 各列表游标的 Scope
+const (
+	CursorScopePosts    = "posts"
+	CursorScopeArticles = "articles"
+	CursorScopeEvents   = "events"
+	CursorScopeFeedback = "feedback"
+)
+
+// keysetPage 游标分页：按 (sortExpr, idExpr) 排序，从游标位置之后读取 limit 条，方向取自游标。
+// 新数据插入不会导致翻页时重复或遗漏，深翻页也不需要扫描前面的记录
+func keysetPage(query *gorm.DB, sortExpr, idExpr string, cursor *utils.Cursor, limit int) *gorm.DB {
+	op, dir := ">", "asc"
+	if cursor.Desc {
+		op, dir = "<", "desc"
+	}
+	if !cursor.IsStart() {
+		query = query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", sortExpr, idExpr, op), cursor.Time, cursor.ID)
+	}
+	return query.Order(sortExpr + " " + dir).Order(idExpr + " " + dir).Limit(limit)
+}
+
+type cursorKeyer interface {
+	CursorKey() (time.Time, uint)
+}
+
+// NextCursor 以本页最后一条记录生成下一页游标；本页不足 limit 条说明已到末尾，返回空字符串
+func NextCursor[T cursorKeyer](cursor *utils.Cursor, items []T, limit int) string {
+	if len(items) == 0 || len(items) < limit {
+		return ""
+	}
+	t, id := items[len(items)-1].CursorKey()
+	return utils.EncodeCursor(utils.Cursor{Scope: cursor.Scope, Time: t, ID: id, Desc: cursor.Desc})
+}
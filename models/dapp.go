@@ -0,0 +1,116 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type Dapp struct {
+	gorm.Model
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Chain         string         `json:"chain"`
+	Category      string         `json:"category"`
+	Website       string         `json:"website"`
+	Contracts     pq.StringArray `gorm:"type:text[]" json:"contracts"`
+	Logo          string         `json:"logo"`
+	Tags          pq.StringArray `gorm:"type:text[]" json:"tags"`
+	Twitter       string         `json:"twitter"`
+	PublisherId   uint           `json:"publisher_id"`
+	Publisher     *User          `gorm:"foreignKey:PublisherId" json:"publisher"`
+	PublishTime   *time.Time     `json:"publish_time"`
+	PublishStatus uint           `gorm:"default:1" json:"publish_status"` // 0:全部 1:待审核 2:已发布
+}
+
+func (d *Dapp) Create(ctx context.Context) error {
+	return db.WithContext(ctx).Create(d).Error
+}
+
+func (d *Dapp) GetByID(ctx context.Context, id uint) error {
+	return db.WithContext(ctx).Preload("Publisher").First(d, id).Error
+}
+
+func (d *Dapp) Update(ctx context.Context) error {
+	if d.ID == 0 {
+		return errors.New("missing Dapp ID")
+	}
+	return db.WithContext(ctx).Save(d).Error
+}
+
+func (d *Dapp) Delete(ctx context.Context) error {
+	if d.ID == 0 {
+		return errors.New("missing Dapp ID")
+	}
+	return db.WithContext(ctx).Delete(d).Error
+}
+
+type DappFilter struct {
+	Keyword       string // 名称或描述关键词
+	Chain         string // 所属链
+	Category      string // 分类
+	Tag           string // 包含某个 tag
+	PublishStatus int    // 发布状态
+	PublisherId   int
+	OrderDesc     bool // 是否按创建时间倒序
+	Page          int  // 当前页码，从 1 开始
+	PageSize      int  // 每页数量，建议默认 10
+}
+
+func QueryDapps(ctx context.Context, filter DappFilter) ([]Dapp, int64, error) {
+	var dapps []Dapp
+	var total int64
+
+	query := db.WithContext(ctx).Preload("Publisher").Model(&Dapp{})
+
+	if filter.Keyword != "" {
+		likePattern := "%" + filter.Keyword + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ?", likePattern, likePattern)
+	}
+
+	if filter.Chain != "" {
+		query = query.Where("chain = ?", filter.Chain)
+	}
+
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+
+	if filter.Tag != "" {
+		query = query.Where("? = ANY (tags)", filter.Tag)
+	}
+
+	if filter.PublishStatus != 0 {
+		query = query.Where("publish_status = ?", filter.PublishStatus)
+	}
+
+	if filter.PublisherId != 0 {
+		query = query.Where("publisher_id = ?", filter.PublisherId)
+	}
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	// 排序
+	if filter.OrderDesc {
+		query = query.Order("created_at desc")
+	} else {
+		query = query.Order("created_at asc")
+	}
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.Offset(offset).Limit(filter.PageSize)
+
+	err := query.Find(&dapps).Error
+	return dapps, total, err
+}
@@ -1,11 +1,14 @@
 package models
 
 import (
+	"context"
 	"errors"
+	"hyperlane/utils"
 	"time"
 
 	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Event struct {
@@ -23,34 +26,63 @@ type Event struct {
 	CoverImg             string         `json:"cover_img"`
 	Tags                 pq.StringArray `gorm:"type:text[]" json:"tags"`
 	Participants         uint           `json:"participants"`
-	Status               uint           `gorm:"default:0" json:"status"`         // 0: 未开始，1: 进行中 2: 已结束 TODO: 定时器更新状态？
-	PublishStatus        uint           `gorm:"default:1" json:"publish_status"` // 0: 所有  1: 待审核 2: 已发布
+	Capacity             uint           `gorm:"default:0" json:"capacity"` // 报名名额，0 表示不限
+	CommentCount         uint           `gorm:"default:0" json:"comment_count"`
+	ViewCount            uint           `gorm:"default:0" json:"view_count"`
+	Status               uint           `gorm:"default:0" json:"status"`         // 0: 未开始，1: 进行中 2: 已结束，由定时任务更新
+	PublishStatus        uint           `gorm:"default:1" json:"publish_status"` // 1:待审核 2:已发布 3:草稿 4:退回修改 5:审核通过 6:已下线 7:已归档
 	PublishTime          *time.Time     `json:"publish_time"`
 	Twitter              string         `json:"twitter"`
+	Sequence             uint           `gorm:"default:0" json:"sequence"` // 每次修改递增，日历订阅据此更新事件
 	UserId               uint           `json:"user_id"`
 	User                 *User          `gorm:"foreignKey:UserId"`
 }
 
-func (e *Event) Create() error {
-	return db.Create(e).Error
+func (e *Event) Create(ctx context.Context) error {
+	return db.WithContext(ctx).Create(e).Error
 }
 
-func (e *Event) GetByID(id uint) error {
-	return db.First(e, id).Error
+func (e *Event) GetByID(ctx context.Context, id uint) error {
+	err := db.WithContext(ctx).First(e, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrEventNotFound
+	}
+	return err
 }
 
-func (e *Event) Update() error {
+// 由浏览量刷新、评论维护的计数列，编辑时不覆盖
+var eventCounterColumns = []string{"view_count", "comment_count"}
+
+// Update 保存活动；参与人数以数据库为准，名额不能低于已报名人数，名额增加后在同一事务内递补候补
+func (e *Event) Update(ctx context.Context) error {
 	if e.ID == 0 {
 		return errors.New("missing event ID")
 	}
-	return db.Save(e).Error
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住活动行，避免与报名、取消并发时覆盖参与人数
+		var current Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, e.ID).Error; err != nil {
+			return err
+		}
+		if e.Capacity > 0 && e.Capacity < current.Participants {
+			return ErrCapacityBelowParticipants
+		}
+		e.Participants = current.Participants
+		e.ViewCount = current.ViewCount
+		e.CommentCount = current.CommentCount
+		e.Sequence++
+		if err := tx.Omit(eventCounterColumns...).Save(e).Error; err != nil {
+			return err
+		}
+		return promoteWaitlist(tx, e)
+	})
 }
 
-func (e *Event) Delete() error {
+func (e *Event) Delete(ctx context.Context) error {
 	if e.ID == 0 {
 		return errors.New("missing event ID")
 	}
-	return db.Delete(e).Error
+	return db.WithContext(ctx).Delete(e).Error
 }
 
 type EventFilter struct {
@@ -66,13 +98,20 @@ type EventFilter struct {
 	PublishStatus int
 	StartDate     *time.Time
 	EndDate       *time.Time
+	EndAfter      *time.Time    // 结束时间晚于该时间
+	Cursor        *utils.Cursor // 游标分页，非空时忽略 Page 和 OrderDesc，按 (start_time, id) 从游标之后读取
+	SkipTotal     bool          // 不统计总数，total 返回 -1
+}
+
+func (e Event) CursorKey() (time.Time, uint) {
+	return e.StartTime, e.ID
 }
 
-func QueryEvents(filter EventFilter) ([]Event, int64, error) {
+func QueryEvents(ctx context.Context, filter EventFilter) ([]Event, int64, error) {
 	var events []Event
 	var total int64
 
-	query := db.Model(&Event{})
+	query := db.WithContext(ctx).Model(&Event{})
 
 	if filter.Keyword != "" {
 		likePattern := "%" + filter.Keyword + "%"
@@ -107,8 +146,23 @@ func QueryEvents(filter EventFilter) ([]Event, int64, error) {
 		query = query.Where("events.created_at BETWEEN ? AND ?", filter.StartDate, filter.EndDate)
 	}
 
+	if filter.EndAfter != nil {
+		query = query.Where("end_time > ?", filter.EndAfter)
+	}
+
 	// 统计总数（不加 limit 和 offset）
-	query.Count(&total)
+	total = -1
+	if !filter.SkipTotal {
+		query.Count(&total)
+	}
+
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	if filter.Cursor != nil {
+		err := keysetPage(query, "events.start_time", "events.id", filter.Cursor, filter.PageSize).Find(&events).Error
+		return events, total, err
+	}
 
 	// 排序
 	if filter.OrderDesc {
@@ -121,12 +175,32 @@ func QueryEvents(filter EventFilter) ([]Event, int64, error) {
 	if filter.Page < 1 {
 		filter.Page = 1
 	}
-	if filter.PageSize <= 0 {
-		filter.PageSize = 10
-	}
 	offset := (filter.Page - 1) * filter.PageSize
 	query = query.Offset(offset).Limit(filter.PageSize)
 
 	err := query.Find(&events).Error
 	return events, total, err
 }
+
+// 根据开始/结束时间推进活动状态，返回更新的活动数
+func UpdateEventStatuses(ctx context.Context, now time.Time) (int64, error) {
+	var affected int64
+
+	res := db.WithContext(ctx).Model(&Event{}).
+		Where("status <> ? AND end_time <= ?", 2, now).
+		UpdateColumn("status", 2)
+	if res.Error != nil {
+		return affected, res.Error
+	}
+	affected += res.RowsAffected
+
+	res = db.WithContext(ctx).Model(&Event{}).
+		Where("status = ? AND start_time <= ? AND end_time > ?", 0, now, now).
+		UpdateColumn("status", 1)
+	if res.Error != nil {
+		return affected, res.Error
+	}
+	affected += res.RowsAffected
+
+	return affected, nil
+}
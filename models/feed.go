@@ -0,0 +1,278 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"hyperlane/utils"
+	"math"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// 帖子信息流模式
+const (
+	FeedFollowing = "following" // 只看关注的作者
+	FeedDiscover  = "discover"  // 全站热门
+	FeedHybrid    = "hybrid"    // 按比例混合关注流和发现流
+)
+
+const maxFeedPageSize = 50
+
+// FeedRanking 排序参数，对应配置 feed.*
+type FeedRanking struct {
+	MixRatio       float64       // 混合流中关注流所占比例
+	HalfLife       time.Duration // 每过一个 HalfLife，互动量需要翻倍才能保持原有排名
+	Window         time.Duration // 只从该时间范围内的帖子中挑选候选，0 表示不限
+	SnapshotTTL    time.Duration // 热度快照保留时间，翻阅超过该时间的游标失效
+	ViewWeight     float64
+	LikeWeight     float64
+	FavoriteWeight float64
+}
+
+func LoadFeedRanking() FeedRanking {
+	r := FeedRanking{
+		MixRatio:       viper.GetFloat64("feed.mixRatio"),
+		HalfLife:       viper.GetDuration("feed.halfLife"),
+		Window:         viper.GetDuration("feed.window"),
+		SnapshotTTL:    viper.GetDuration("feed.snapshotTTL"),
+		ViewWeight:     viper.GetFloat64("feed.weights.views"),
+		LikeWeight:     viper.GetFloat64("feed.weights.likes"),
+		FavoriteWeight: viper.GetFloat64("feed.weights.favorites"),
+	}
+	r.MixRatio = math.Min(math.Max(r.MixRatio, 0), 1)
+	r.ViewWeight = math.Max(r.ViewWeight, 0)
+	r.LikeWeight = math.Max(r.LikeWeight, 0)
+	r.FavoriteWeight = math.Max(r.FavoriteWeight, 0)
+	if r.HalfLife <= 0 {
+		r.HalfLife = 24 * time.Hour
+	}
+	if r.SnapshotTTL <= 0 {
+		r.SnapshotTTL = time.Hour
+	}
+	return r
+}
+
+// 热度分：log2(1 + 加权互动量) + 发布时间 / HalfLife。
+// 互动量随浏览、点赞、收藏实时变化，直接按它做 keyset 分页会让帖子在页与页之间跳过或重复，
+// 因此分数由 RefreshFeedScores 定期写入 feed_scores 快照，翻页时固定使用第一页所在的快照
+const feedScoreExpr = `LN(1 + CAST(? AS double precision) * posts.view_count + CAST(? AS double precision) * posts.like_count + CAST(? AS double precision) * posts.favorite_count) / LN(2)` +
+	` + CAST(EXTRACT(EPOCH FROM posts.created_at) AS double precision) / CAST(? AS double precision)`
+
+func (r FeedRanking) scoreArgs() []interface{} {
+	return []interface{}{r.ViewWeight, r.LikeWeight, r.FavoriteWeight, r.HalfLife.Seconds()}
+}
+
+// FeedQuery 信息流查询，Cursor 为上一页返回的 next_cursor，第一页为空
+type FeedQuery struct {
+	Mode     string
+	UserId   uint // 当前用户，关注流和混合流必填
+	PageSize int
+	Cursor   string
+}
+
+// 两路候选各自的 keyset 位置；Done 表示该路已读完
+type feedPosition struct {
+	Score float64 `json:"s,omitempty"`
+	ID    uint    `json:"i,omitempty"`
+	Done  bool    `json:"x,omitempty"`
+}
+
+// feedCursor 信息流游标。Snapshot 固定第一页使用的热度快照，之后发布的帖子和互动量的变化
+// 不会影响正在翻阅的列表
+type feedCursor struct {
+	Scope     string       `json:"s"`
+	Mode      string       `json:"m"`
+	UserId    uint         `json:"u,omitempty"`
+	Snapshot  int64        `json:"a"`
+	Following feedPosition `json:"f"`
+	Discover  feedPosition `json:"d"`
+}
+
+const feedCursorScope = "feed"
+
+// QueryFeed 按热度返回一页信息流和下一页游标，没有更多内容时游标为空
+func QueryFeed(ctx context.Context, q FeedQuery, r FeedRanking) ([]Post, string, error) {
+	pageSize := q.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageSize > maxFeedPageSize {
+		pageSize = maxFeedPageSize
+	}
+
+	cursor := feedCursor{Scope: feedCursorScope, Mode: q.Mode, UserId: q.UserId}
+	if q.Cursor != "" {
+		var decoded feedCursor
+		err := utils.DecodeSigned(q.Cursor, &decoded)
+		if err != nil || decoded.Scope != feedCursorScope || decoded.Mode != q.Mode || decoded.UserId != q.UserId {
+			return nil, "", utils.ErrInvalidCursor
+		}
+		// 快照已被清理时需要从第一页重新开始
+		exists, err := feedSnapshotExists(ctx, time.UnixMicro(decoded.Snapshot))
+		if err != nil {
+			return nil, "", err
+		}
+		if !exists {
+			return nil, "", utils.ErrInvalidCursor
+		}
+		cursor = decoded
+	} else {
+		snapshot, err := latestFeedSnapshot(ctx, r)
+		if err != nil {
+			return nil, "", err
+		}
+		if snapshot.IsZero() {
+			return []Post{}, "", nil
+		}
+		cursor.Snapshot = snapshot.UnixMicro()
+	}
+
+	ratio := r.MixRatio
+	switch q.Mode {
+	case FeedFollowing:
+		ratio = 1
+		cursor.Discover.Done = true
+	case FeedDiscover:
+		ratio = 0
+		cursor.Following.Done = true
+	}
+	snapshot := time.UnixMicro(cursor.Snapshot)
+
+	// 每路最多取一整页，按比例取不完的部分留到下一页
+	var following, discover []Post
+	var err error
+	if !cursor.Following.Done {
+		following, err = feedCandidates(ctx, FeedFollowing, q.UserId, false, snapshot, cursor.Following, pageSize)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if !cursor.Discover.Done {
+		// 混合流中发现流排除关注流已覆盖的作者，两路不会出现同一帖子
+		discover, err = feedCandidates(ctx, FeedDiscover, q.UserId, q.Mode == FeedHybrid, snapshot, cursor.Discover, pageSize)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	posts, fi, di := mixFeed(following, discover, ratio, pageSize)
+	cursor.Following = advanceFeed(cursor.Following, following, fi, pageSize)
+	cursor.Discover = advanceFeed(cursor.Discover, discover, di, pageSize)
+	if cursor.Following.Done && cursor.Discover.Done {
+		return posts, "", nil
+	}
+	return posts, utils.EncodeSigned(cursor), nil
+}
+
+// mixFeed 按比例交替合并两路候选，返回本页内容和两路各用掉的数量；
+// 关注流已取数量低于目标比例时优先取关注流，某一路不足时由另一路补齐
+func mixFeed(following, discover []Post, ratio float64, limit int) ([]Post, int, int) {
+	posts := make([]Post, 0, limit)
+	fi, di := 0, 0
+	for len(posts) < limit && (fi < len(following) || di < len(discover)) {
+		wantFollowing := float64(fi) < ratio*float64(len(posts)+1)
+		if fi < len(following) && (wantFollowing || di >= len(discover)) {
+			posts = append(posts, following[fi])
+			fi++
+		} else {
+			posts = append(posts, discover[di])
+			di++
+		}
+	}
+	return posts, fi, di
+}
+
+// 位置移到本页从该路取到的最后一条；取出的数量不足一页且已全部用完说明该路读完
+func advanceFeed(pos feedPosition, candidates []Post, taken, limit int) feedPosition {
+	if pos.Done {
+		return pos
+	}
+	if taken > 0 {
+		last := candidates[taken-1]
+		pos.Score, pos.ID = last.Score, last.ID
+	}
+	if len(candidates) < limit && taken == len(candidates) {
+		pos.Done = true
+	}
+	return pos
+}
+
+// feedCandidates 按快照中的热度读取一路候选；excludeFollowed 时排除自己和已关注作者的帖子
+func feedCandidates(ctx context.Context, stream string, userId uint, excludeFollowed bool, snapshot time.Time, after feedPosition, limit int) ([]Post, error) {
+	var posts []Post
+	query := db.WithContext(ctx).Preload("User").Model(&Post{}).
+		Select("posts.*, feed_scores.score").
+		Joins("JOIN feed_scores ON feed_scores.post_id = posts.id AND feed_scores.snapshot_at = ?", snapshot)
+
+	followed := func() *gorm.DB {
+		return db.WithContext(ctx).Model(&Follow{}).Select("following_id").Where("follower_id = ?", userId)
+	}
+	switch {
+	case stream == FeedFollowing:
+		query = query.Where("posts.user_id IN (?)", followed())
+	case excludeFollowed && userId != 0:
+		query = query.Where("posts.user_id NOT IN (?)", followed()).Where("posts.user_id <> ?", userId)
+	}
+
+	if after.ID != 0 {
+		query = query.Where("(feed_scores.score, posts.id) < (?, ?)", after.Score, after.ID)
+	}
+
+	err := query.Order("feed_scores.score desc").Order("posts.id desc").Limit(limit).Find(&posts).Error
+	return posts, err
+}
+
+// RefreshFeedScores 按当前互动量为窗口内的帖子生成一份热度快照，并清理超过 SnapshotTTL 的旧快照，
+// 返回快照时间。之后发布的帖子要等下一份快照才会出现在信息流中
+func RefreshFeedScores(ctx context.Context, now time.Time, r FeedRanking) (time.Time, error) {
+	// 与游标中的微秒时间戳一致
+	snapshot := time.UnixMicro(now.UnixMicro())
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 多个实例同时生成时排队，避免重复写入同一批数据
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('feed_scores'))").Error; err != nil {
+			return err
+		}
+		args := append([]interface{}{snapshot}, r.scoreArgs()...)
+		insert := "INSERT INTO feed_scores (snapshot_at, post_id, score) SELECT CAST(? AS timestamptz), posts.id, " + feedScoreExpr +
+			" FROM posts WHERE posts.deleted_at IS NULL AND posts.created_at <= ?"
+		args = append(args, snapshot)
+		if r.Window > 0 {
+			insert += " AND posts.created_at > ?"
+			args = append(args, snapshot.Add(-r.Window))
+		}
+		if err := tx.Exec(insert, args...).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM feed_scores WHERE snapshot_at < ?", snapshot.Add(-r.SnapshotTTL)).Error
+	})
+	return snapshot, err
+}
+
+// 最新的热度快照；还没有快照时（例如首次部署，定时任务尚未执行）当场生成一份，
+// 窗口内没有帖子时返回零值
+func latestFeedSnapshot(ctx context.Context, r FeedRanking) (time.Time, error) {
+	var latest sql.NullTime
+	if err := db.WithContext(ctx).Raw("SELECT MAX(snapshot_at) FROM feed_scores").Scan(&latest).Error; err != nil {
+		return time.Time{}, err
+	}
+	if latest.Valid {
+		return latest.Time, nil
+	}
+	snapshot, err := RefreshFeedScores(ctx, time.Now(), r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	exists, err := feedSnapshotExists(ctx, snapshot)
+	if err != nil || !exists {
+		return time.Time{}, err
+	}
+	return snapshot, nil
+}
+
+func feedSnapshotExists(ctx context.Context, snapshot time.Time) (bool, error) {
+	var exists bool
+	err := db.WithContext(ctx).Raw("SELECT EXISTS (SELECT 1 FROM feed_scores WHERE snapshot_at = ?)", snapshot).Scan(&exists).Error
+	return exists, err
+}
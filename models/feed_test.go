@@ -0,0 +1,148 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func feedPosts(ids ...uint) []Post {
+	posts := make([]Post, len(ids))
+	for i, id := range ids {
+		posts[i] = Post{Model: gorm.Model{ID: id}, Score: float64(100 - id)}
+	}
+	return posts
+}
+
+func postIds(posts []Post) []uint {
+	ids := make([]uint, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestMixFeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		following []Post
+		discover  []Post
+		ratio     float64
+		limit     int
+		want      []uint
+	}{
+		{name: "Ratio 0.7", following: feedPosts(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), discover: feedPosts(11, 12, 13, 14, 15, 16, 17, 18, 19, 20), ratio: 0.7, limit: 10,
+			want: []uint{1, 2, 3, 11, 4, 5, 12, 6, 7, 13}},
+		{name: "Following only", following: feedPosts(1, 2, 3), discover: nil, ratio: 1, limit: 2, want: []uint{1, 2}},
+		{name: "Discover fills short following", following: feedPosts(1), discover: feedPosts(11, 12, 13), ratio: 0.7, limit: 4, want: []uint{1, 11, 12, 13}},
+		{name: "Following fills short discover", following: feedPosts(1, 2, 3, 4), discover: feedPosts(11), ratio: 0.5, limit: 4, want: []uint{1, 11, 2, 3}},
+		{name: "Both empty", ratio: 0.7, limit: 4, want: []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fi, di := mixFeed(tt.following, tt.discover, tt.ratio, tt.limit)
+			ids := postIds(got)
+			if len(ids) != len(tt.want) {
+				t.Fatalf("mixFeed() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("mixFeed() = %v, want %v", ids, tt.want)
+				}
+			}
+			if fi+di != len(got) {
+				t.Errorf("taken %d+%d, want %d", fi, di, len(got))
+			}
+		})
+	}
+}
+
+func TestAdvanceFeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []Post
+		taken      int
+		limit      int
+		wantID     uint
+		wantDone   bool
+	}{
+		{name: "Partly used", candidates: feedPosts(1, 2, 3), taken: 2, limit: 3, wantID: 2},
+		{name: "Full page used", candidates: feedPosts(1, 2, 3), taken: 3, limit: 3, wantID: 3},
+		{name: "Short page used up", candidates: feedPosts(1, 2), taken: 2, limit: 3, wantID: 2, wantDone: true},
+		{name: "Short page partly used", candidates: feedPosts(1, 2), taken: 1, limit: 3, wantID: 1},
+		{name: "Nothing left", candidates: nil, taken: 0, limit: 3, wantDone: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := advanceFeed(feedPosition{}, tt.candidates, tt.taken, tt.limit)
+			if got.ID != tt.wantID || got.Done != tt.wantDone {
+				t.Errorf("advanceFeed() = %+v, want id %d done %v", got, tt.wantID, tt.wantDone)
+			}
+			if tt.wantID != 0 && got.Score != float64(100-tt.wantID) {
+				t.Errorf("advanceFeed() score = %v", got.Score)
+			}
+		})
+	}
+}
+
+// 需要 PostgreSQL，见 useTestDB。翻页过程中互动量变化，帖子仍然每条只出现一次
+func TestQueryFeedStableAcrossPages(t *testing.T) {
+	tx := useTestDB(t)
+	ctx := context.Background()
+	r := FeedRanking{HalfLife: 24 * time.Hour, Window: 720 * time.Hour, SnapshotTTL: time.Hour, LikeWeight: 1}
+
+	user := User{Email: fmt.Sprintf("feed-%d@example.com", time.Now().UnixNano())}
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	created := time.Now().Add(-time.Hour)
+	var own []uint
+	for i := 0; i < 6; i++ {
+		post := Post{Title: fmt.Sprintf("feed %d", i), UserId: user.ID, LikeCount: uint(10 * (6 - i))}
+		post.CreatedAt = created
+		if err := tx.Create(&post).Error; err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+		own = append(own, post.ID)
+	}
+	if _, err := RefreshFeedScores(ctx, time.Now(), r); err != nil {
+		t.Fatalf("RefreshFeedScores: %v", err)
+	}
+
+	seen := map[uint]int{}
+	cursor := ""
+	for page := 0; ; page++ {
+		posts, next, err := QueryFeed(ctx, FeedQuery{Mode: FeedDiscover, PageSize: 2, Cursor: cursor}, r)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		for _, p := range posts {
+			seen[p.ID]++
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+
+		// 翻到下一页之前，排在后面的帖子点赞数暴涨，已看过的帖子点赞数清零
+		if page == 0 {
+			if err := tx.Model(&Post{}).Where("id = ?", own[len(own)-1]).UpdateColumn("like_count", 10000).Error; err != nil {
+				t.Fatalf("update likes: %v", err)
+			}
+			if err := tx.Model(&Post{}).Where("id IN ?", postIds(posts)).UpdateColumn("like_count", 0).Error; err != nil {
+				t.Fatalf("update likes: %v", err)
+			}
+		}
+	}
+
+	for _, id := range own {
+		if seen[id] != 1 {
+			t.Errorf("post %d seen %d times, want 1", id, seen[id])
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"hyperlane/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Feedback struct {
 	gorm.Model
@@ -11,24 +17,41 @@ type Feedback struct {
 	User    *User `gorm:"foreignKey:UserId" json:"user"`
 }
 
-func (f *Feedback) Create() error {
-	return db.Create(f).Error
+func (f *Feedback) Create(ctx context.Context) error {
+	return db.WithContext(ctx).Create(f).Error
 }
 
 type FeedbackFilter struct {
 	OrderDesc bool
-	Page      int // 当前页码，从 1 开始
-	PageSize  int // 每页数量，建议默认 10
+	Page      int           // 当前页码，从 1 开始
+	PageSize  int           // 每页数量，建议默认 10
+	Cursor    *utils.Cursor // 游标分页，非空时忽略 Page 和 OrderDesc，按 (created_at, id) 从游标之后读取
+	SkipTotal bool          // 不统计总数，total 返回 -1
+}
+
+func (f Feedback) CursorKey() (time.Time, uint) {
+	return f.CreatedAt, f.ID
 }
 
-func QueryFeedback(filter FeedbackFilter) ([]Feedback, int64, error) {
+func QueryFeedback(ctx context.Context, filter FeedbackFilter) ([]Feedback, int64, error) {
 	var feedbacks []Feedback
 	var total int64
 
-	query := db.Preload("User").Model(&Feedback{})
+	query := db.WithContext(ctx).Preload("User").Model(&Feedback{})
 
 	// 统计总数（不加 limit 和 offset）
-	query.Count(&total)
+	total = -1
+	if !filter.SkipTotal {
+		query.Count(&total)
+	}
+
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	if filter.Cursor != nil {
+		err := keysetPage(query, "created_at", "id", filter.Cursor, filter.PageSize).Find(&feedbacks).Error
+		return feedbacks, total, err
+	}
 
 	// 排序
 	if filter.OrderDesc {
@@ -41,9 +64,6 @@ func QueryFeedback(filter FeedbackFilter) ([]Feedback, int64, error) {
 	if filter.Page < 1 {
 		filter.Page = 1
 	}
-	if filter.PageSize <= 0 {
-		filter.PageSize = 10
-	}
 	offset := (filter.Page - 1) * filter.PageSize
 	query = query.Offset(offset).Limit(filter.PageSize)
 
@@ -1,25 +1,32 @@
 package models
 
 import (
-	"hyperlane/config"
+	"context"
+
+	"gorm.io/gorm"
 )
 
-var db = config.DB
+var db *gorm.DB
+
+// SetDB 注入数据库连接，所有模型操作都使用该连接
+func SetDB(database *gorm.DB) {
+	db = database
+}
 
-func init() {
-	db.AutoMigrate(&Permission{})
-	db.AutoMigrate(&PermissionGroup{})
-	db.AutoMigrate(&Role{})
-	db.AutoMigrate(&User{})
-	db.AutoMigrate(&Event{})
-	db.AutoMigrate(&Recap{})
-	db.AutoMigrate(&Article{})
-	db.AutoMigrate(&Feedback{})
-	db.AutoMigrate(&Post{})
-	db.AutoMigrate(&PostLike{})
-	db.AutoMigrate(&PostFavorite{})
-	db.AutoMigrate(&DailyStats{})
-	db.AutoMigrate(&Follow{})
+// Ping 检查数据库连接是否可用，用于就绪探针
+func Ping(ctx context.Context) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
 
-	InitRolesAndPermissions()
+// Seed 加载检索配置并初始化角色权限，表结构由 migrations 包维护
+func Seed(ctx context.Context) error {
+	InitSearch(ctx)
+	if err := InitRolesAndPermissions(ctx); err != nil {
+		return err
+	}
+	return upgradeRolesAndPermissions(ctx)
 }
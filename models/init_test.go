@@ -0,0 +1,158 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"hyperlane/logger"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunConn 让 DryRun 下的事务也不连接数据库；DryRun 不执行语句，查询方法不会被调用
+type dryRunConn struct{}
+
+var errDryRun = errors.New("dry run")
+
+func (*dryRunConn) PrepareContext(context.Context, string) (*sql.Stmt, error) { return nil, errDryRun }
+func (*dryRunConn) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errDryRun
+}
+func (*dryRunConn) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errDryRun
+}
+func (*dryRunConn) QueryRowContext(context.Context, string, ...interface{}) *sql.Row { return nil }
+func (c *dryRunConn) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) { return c, nil }
+func (*dryRunConn) Commit() error                                                    { return nil }
+func (*dryRunConn) Rollback() error                                                  { return nil }
+
+// DryRun 只生成 SQL 不连接数据库，用于检查模型查询输出的日志
+func useDryRunDB(t *testing.T) {
+	t.Helper()
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: &dryRunConn{}}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.NewGormLogger(0),
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+	prev := db
+	SetDB(gdb)
+	t.Cleanup(func() { SetDB(prev) })
+}
+
+// 把日志改为 JSON 写入缓冲区，用于检查 SQL 日志
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	prevLog := logger.Log
+	t.Cleanup(func() { logger.Log = prevLog })
+	var buf bytes.Buffer
+	logger.Log = logrus.New()
+	logger.Log.SetFormatter(&logrus.JSONFormatter{})
+	logger.Log.SetLevel(logrus.DebugLevel)
+	logger.Log.Out = &buf
+	return &buf
+}
+
+// 返回缓冲区中带 sql 字段的日志
+func sqlEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("invalid log line %s: %v", line, err)
+		}
+		if entry["sql"] != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestQueryLogsRequestID(t *testing.T) {
+	useDryRunDB(t)
+	buf := captureLog(t)
+
+	ctx := logger.ContextWithRequestID(context.Background(), "req-1")
+	tests := []struct {
+		name  string
+		query func() error
+	}{
+		{name: "Method", query: func() error { return (&Post{}).GetByID(ctx, 1) }},
+		{name: "Function", query: func() error { _, err := GetUserById(ctx, 1); return err }},
+		{name: "List", query: func() error { _, _, err := QueryDapps(ctx, DappFilter{}); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.query()
+
+			entries := sqlEntries(t, buf)
+			if len(entries) == 0 {
+				t.Fatalf("no sql logged: %s", buf.String())
+			}
+			for _, entry := range entries {
+				if entry["request_id"] != "req-1" {
+					t.Errorf("request_id = %v, want req-1 in %v", entry["request_id"], entry["sql"])
+				}
+			}
+		})
+	}
+}
+
+// 编辑内容时不能用读取时的旧值覆盖其他写入维护的计数列
+func TestUpdateKeepsCounters(t *testing.T) {
+	useDryRunDB(t)
+	buf := captureLog(t)
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		table   string
+		update  func() error
+		columns []string
+	}{
+		{name: "Post", table: "posts", update: func() error { return (&Post{Model: gorm.Model{ID: 1}}).Update(ctx) },
+			columns: postCounterColumns},
+		{name: "Article", table: "articles", update: func() error { return (&Article{Model: gorm.Model{ID: 1}}).Update(ctx) },
+			columns: articleCounterColumns},
+		{name: "Event", table: "events", update: func() error { return (&Event{Model: gorm.Model{ID: 1}}).Update(ctx) },
+			columns: eventCounterColumns},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			if err := tt.update(); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+
+			updated := false
+			for _, entry := range sqlEntries(t, buf) {
+				sql, _ := entry["sql"].(string)
+				if !strings.HasPrefix(sql, `UPDATE "`+tt.table+`"`) {
+					continue
+				}
+				updated = true
+				for _, col := range tt.columns {
+					if strings.Contains(sql, `"`+col+`"`) {
+						t.Errorf("update writes %s: %s", col, sql)
+					}
+				}
+			}
+			if !updated {
+				t.Fatalf("no update on %s: %s", tt.table, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	JobStatusRunning = "running"
+	JobStatusSuccess = "success"
+	JobStatusFailed  = "failed"
+)
+
+// 定时任务执行记录，同一任务同一调度时刻只会有一条
+type JobRun struct {
+	gorm.Model
+	Name        string     `gorm:"uniqueIndex:idx_job_slot;not null" json:"name"`
+	ScheduledAt time.Time  `gorm:"uniqueIndex:idx_job_slot;not null" json:"scheduled_at"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+	Status      string     `gorm:"index" json:"status"` // running / success / failed
+	Error       string     `gorm:"type:text" json:"error"`
+	Host        string     `json:"host"`
+}
+
+// TryAdvisoryLock 尝试获取 Postgres 会话级 advisory lock，
+// 锁绑定在单独的连接上，调用 unlock 释放锁并归还连接
+func TryAdvisoryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock = func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// StartJobRun 登记一次任务执行，若该调度时刻已被其他实例执行过则返回 false
+func StartJobRun(ctx context.Context, name string, scheduledAt time.Time, host string) (*JobRun, bool, error) {
+	run := JobRun{
+		Name:        name,
+		ScheduledAt: scheduledAt,
+		StartedAt:   time.Now(),
+		Status:      JobStatusRunning,
+		Host:        host,
+	}
+
+	res := db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&run)
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	return &run, res.RowsAffected > 0, nil
+}
+
+func (r *JobRun) Finish(ctx context.Context, runErr error) error {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.DurationMs = now.Sub(r.StartedAt).Milliseconds()
+	r.Status = JobStatusSuccess
+	if runErr != nil {
+		r.Status = JobStatusFailed
+		r.Error = runErr.Error()
+	}
+	return db.WithContext(ctx).Save(r).Error
+}
+
+// 清理指定时间之前的执行记录
+func DeleteJobRunsBefore(ctx context.Context, t time.Time) (int64, error) {
+	res := db.WithContext(ctx).Unscoped().Where("scheduled_at < ?", t).Delete(&JobRun{})
+	return res.RowsAffected, res.Error
+}
+
+type JobRunFilter struct {
+	Name     string
+	Status   string
+	Page     int // 当前页码，从 1 开始
+	PageSize int // 每页数量，建议默认 10
+}
+
+func QueryJobRuns(ctx context.Context, filter JobRunFilter) ([]JobRun, int64, error) {
+	var runs []JobRun
+	var total int64
+
+	query := db.WithContext(ctx).Model(&JobRun{})
+
+	if filter.Name != "" {
+		query = query.Where("name = ?", filter.Name)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	query = query.Order("scheduled_at desc")
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.Offset(offset).Limit(filter.PageSize)
+
+	err := query.Find(&runs).Error
+	return runs, total, err
+}
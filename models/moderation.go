@@ -0,0 +1,410 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hyperlane/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrClaimedByOther = utils.NewAppError(http.StatusConflict, "CLAIMED_BY_OTHER", "item is claimed by another reviewer")
+	ErrClaimNotFound  = utils.NewAppError(http.StatusNotFound, "CLAIM_NOT_FOUND", "claim not found")
+	ErrNotPending     = utils.NewAppError(http.StatusConflict, "NOT_PENDING", "item is not pending review")
+)
+
+// ModerationTypeRevision 已发布博客的待审核修订。队列中 target_id 为博客 ID，认领也按博客 ID 记录，
+// 通过或退回走修订审核接口（/v1/blogs/:id/revisions/:revision_id/approve|reject）
+const ModerationTypeRevision = "blog_revision"
+
+// 认领筛选
+const (
+	ModerationClaimMine      = "mine"
+	ModerationClaimUnclaimed = "unclaimed"
+)
+
+// ModerationClaim 审核认领，过期后其他审核人可以重新认领
+type ModerationClaim struct {
+	TargetType string    `gorm:"primaryKey" json:"target_type"`
+	TargetId   uint      `gorm:"primaryKey" json:"target_id"`
+	ReviewerId uint      `json:"reviewer_id"`
+	ClaimedAt  time.Time `json:"claimed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ModerationItem 审核队列中的一条待审核内容
+type ModerationItem struct {
+	TargetType     string          `json:"target_type"`
+	TargetId       uint            `json:"target_id"`
+	RevisionId     *uint           `json:"revision_id,omitempty"` // 待审核修订的 ID，仅 blog_revision
+	Title          string          `json:"title"`
+	AuthorId       uint            `json:"author_id"`
+	Author         *User           `gorm:"-" json:"author"`
+	SubmittedAt    time.Time       `json:"submitted_at"` // 最近一次进入待审核的时间
+	AgeSeconds     int64           `gorm:"-" json:"age_seconds"`
+	ClaimedBy      *uint           `json:"claimed_by"`
+	ClaimExpiresAt *time.Time      `json:"claim_expires_at"`
+	History        []ContentReview `gorm:"-" json:"history"`
+}
+
+type ModerationFilter struct {
+	Types      []string // 审核人有审核权限的内容类型
+	ReviewerId uint
+	Claim      string // mine / unclaimed，空表示全部
+	Page       int
+	PageSize   int
+}
+
+func claimTTL() time.Duration {
+	if ttl := viper.GetDuration("moderation.claimTTL"); ttl > 0 {
+		return ttl
+	}
+	return 30 * time.Minute
+}
+
+func slaTarget() time.Duration {
+	if target := viper.GetDuration("moderation.slaTarget"); target > 0 {
+		return target
+	}
+	return 48 * time.Hour
+}
+
+// 各类型待审核内容合并为一个子查询，包含进入待审核的时间和未过期的认领
+func moderationQueue(ctx context.Context, types []string, now time.Time) *gorm.DB {
+	var parts []string
+	var args []interface{}
+	for _, typ := range types {
+		if typ == ModerationTypeRevision {
+			// 修订提交时即进入待审核，作者为修改人
+			parts = append(parts, `SELECT CAST(? AS text) AS target_type, a.id AS target_id, r.id AS revision_id, r.title, r.editor_id AS author_id,
+				r.created_at AS submitted_at, c.reviewer_id AS claimed_by, c.expires_at AS claim_expires_at
+				FROM articles a
+				JOIN article_revisions r ON r.id = a.pending_revision_id AND r.status = ? AND r.deleted_at IS NULL
+				LEFT JOIN moderation_claims c ON c.target_type = ? AND c.target_id = a.id AND c.expires_at > ?
+				WHERE a.deleted_at IS NULL`)
+			args = append(args, typ, RevisionPending, typ, now)
+			continue
+		}
+		target, ok := reviewTargets[typ]
+		if !ok {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf(`SELECT CAST(? AS text) AS target_type, t.id AS target_id, CAST(NULL AS bigint) AS revision_id, t.title, t.%s AS author_id,
+			COALESCE((SELECT MAX(r.created_at) FROM content_reviews r
+				WHERE r.target_type = ? AND r.target_id = t.id AND r.to_status = ? AND r.deleted_at IS NULL), t.created_at) AS submitted_at,
+			c.reviewer_id AS claimed_by, c.expires_at AS claim_expires_at
+			FROM %s t
+			LEFT JOIN moderation_claims c ON c.target_type = ? AND c.target_id = t.id AND c.expires_at > ?
+			WHERE t.deleted_at IS NULL AND t.publish_status = ?`, target.owner, target.table))
+		args = append(args, typ, typ, PublishStatusSubmitted, typ, now, PublishStatusSubmitted)
+	}
+	return db.WithContext(ctx).Table("("+strings.Join(parts, " UNION ALL ")+") AS q", args...)
+}
+
+// QueryModerationQueue 按等待时间从长到短返回待审核内容，附带作者和审核历史
+func QueryModerationQueue(ctx context.Context, filter ModerationFilter) ([]ModerationItem, int64, error) {
+	items := []ModerationItem{}
+	if len(filter.Types) == 0 {
+		return items, 0, nil
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	now := time.Now()
+	query := moderationQueue(ctx, filter.Types, now)
+	switch filter.Claim {
+	case ModerationClaimMine:
+		query = query.Where("claimed_by = ?", filter.ReviewerId)
+	case ModerationClaimUnclaimed:
+		query = query.Where("claimed_by IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("submitted_at asc").Order("target_type asc").Order("target_id asc").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := loadModerationDetails(ctx, items, now); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func loadModerationDetails(ctx context.Context, items []ModerationItem, now time.Time) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	authorIds := make([]uint, 0, len(items))
+	targetIds := make(map[string][]uint)
+	for _, item := range items {
+		authorIds = append(authorIds, item.AuthorId)
+		targetIds[historyType(item.TargetType)] = append(targetIds[historyType(item.TargetType)], item.TargetId)
+	}
+
+	var authors []User
+	if err := db.WithContext(ctx).Where("id IN ?", authorIds).Find(&authors).Error; err != nil {
+		return err
+	}
+	authorById := make(map[uint]*User, len(authors))
+	for i := range authors {
+		authorById[authors[i].ID] = &authors[i]
+	}
+
+	history := make(map[string][]ContentReview)
+	for typ, ids := range targetIds {
+		var reviews []ContentReview
+		err := db.WithContext(ctx).Preload("Reviewer").
+			Where("target_type = ? AND target_id IN ?", typ, ids).
+			Order("created_at asc").
+			Find(&reviews).Error
+		if err != nil {
+			return err
+		}
+		for _, r := range reviews {
+			key := fmt.Sprintf("%s:%d", typ, r.TargetId)
+			history[key] = append(history[key], r)
+		}
+	}
+
+	for i := range items {
+		items[i].Author = authorById[items[i].AuthorId]
+		items[i].AgeSeconds = int64(now.Sub(items[i].SubmittedAt).Seconds())
+		items[i].History = history[fmt.Sprintf("%s:%d", historyType(items[i].TargetType), items[i].TargetId)]
+		if items[i].History == nil {
+			items[i].History = []ContentReview{}
+		}
+	}
+	return nil
+}
+
+// 修订的审核记录写在所属博客下
+func historyType(targetType string) string {
+	if targetType == ModerationTypeRevision {
+		return CommentTargetBlog
+	}
+	return targetType
+}
+
+// 锁住待审核内容所在的行，不处于待审核时返回 ErrNotPending
+func lockPending(tx *gorm.DB, targetType string, id uint) error {
+	if targetType == ModerationTypeRevision {
+		article, err := lockArticle(tx, id)
+		if err != nil {
+			return err
+		}
+		if article.PendingRevisionId == nil {
+			return ErrNotPending
+		}
+		return nil
+	}
+
+	target, ok := reviewTargets[targetType]
+	if !ok {
+		return ErrReviewTargetNotFound
+	}
+	var row struct{ PublishStatus uint }
+	err := tx.Table(target.table).
+		Select("publish_status").
+		Where("id = ? AND deleted_at IS NULL", id).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReviewTargetNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if row.PublishStatus != PublishStatusSubmitted {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// ClaimContent 认领待审核内容；自己已认领时续期，他人的认领未过期时返回 ErrClaimedByOther
+func ClaimContent(ctx context.Context, targetType string, id, reviewerId uint) (*ModerationClaim, error) {
+	now := time.Now()
+	claim := ModerationClaim{
+		TargetType: targetType,
+		TargetId:   id,
+		ReviewerId: reviewerId,
+		ClaimedAt:  now,
+		ExpiresAt:  now.Add(claimTTL()),
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 与 TransitionContent、ReviewArticleRevision 锁同一行，认领和审核不会交错
+		if err := lockPending(tx, targetType, id); err != nil {
+			return err
+		}
+
+		res := tx.Exec(`INSERT INTO moderation_claims (target_type, target_id, reviewer_id, claimed_at, expires_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (target_type, target_id) DO UPDATE
+			SET reviewer_id = EXCLUDED.reviewer_id, claimed_at = EXCLUDED.claimed_at, expires_at = EXCLUDED.expires_at
+			WHERE moderation_claims.reviewer_id = EXCLUDED.reviewer_id OR moderation_claims.expires_at <= ?`,
+			claim.TargetType, claim.TargetId, claim.ReviewerId, claim.ClaimedAt, claim.ExpiresAt, now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrClaimedByOther
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// UnclaimContent 释放自己的认领
+func UnclaimContent(ctx context.Context, targetType string, id, reviewerId uint) error {
+	res := db.WithContext(ctx).Where("target_type = ? AND target_id = ? AND reviewer_id = ? AND expires_at > ?", targetType, id, reviewerId, time.Now()).
+		Delete(&ModerationClaim{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrClaimNotFound
+	}
+	return nil
+}
+
+// 内容被他人认领且未过期时拒绝审核操作
+func checkClaim(tx *gorm.DB, targetType string, id, reviewerId uint) error {
+	var claim ModerationClaim
+	err := tx.Where("target_type = ? AND target_id = ? AND expires_at > ?", targetType, id, time.Now()).Take(&claim).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if claim.ReviewerId != reviewerId {
+		return ErrClaimedByOther
+	}
+	return nil
+}
+
+// ModerationStats 一类内容的审核时效
+type ModerationStats struct {
+	TargetType           string  `json:"target_type"`
+	Pending              int64   `json:"pending"`
+	Claimed              int64   `json:"claimed"`
+	Overdue              int64   `json:"overdue"` // 等待时间已超过 SLA 目标
+	OldestPendingSeconds int64   `json:"oldest_pending_seconds"`
+	Reviewed             int64   `json:"reviewed"` // 统计窗口内对待审核内容作出的决定（通过或退回）
+	AvgSeconds           float64 `json:"avg_seconds"`
+	P50Seconds           float64 `json:"p50_seconds"`
+	P90Seconds           float64 `json:"p90_seconds"`
+	WithinSLA            float64 `json:"within_sla"` // 在 SLA 目标内作出决定的比例
+}
+
+type ModerationStatsReport struct {
+	SLATargetSeconds int64             `json:"sla_target_seconds"`
+	Since            time.Time         `json:"since"`
+	Types            []ModerationStats `json:"types"`
+}
+
+// GetModerationStats 统计当前积压情况，以及 since 之后从进入待审核到作出决定的耗时
+func GetModerationStats(ctx context.Context, types []string, since time.Time) (*ModerationStatsReport, error) {
+	now := time.Now()
+	sla := slaTarget()
+	report := &ModerationStatsReport{
+		SLATargetSeconds: int64(sla.Seconds()),
+		Since:            since,
+		Types:            []ModerationStats{},
+	}
+	if len(types) == 0 {
+		return report, nil
+	}
+
+	var pending []struct {
+		TargetType string
+		Pending    int64
+		Claimed    int64
+		Overdue    int64
+		Oldest     *time.Time
+	}
+	err := moderationQueue(ctx, types, now).
+		Select("target_type, COUNT(*) AS pending, COUNT(claimed_by) AS claimed, "+
+			"COUNT(*) FILTER (WHERE submitted_at < ?) AS overdue, MIN(submitted_at) AS oldest", now.Add(-sla)).
+		Group("target_type").
+		Scan(&pending).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, typ := range types {
+		target, ok := reviewTargets[typ]
+		if !ok && typ != ModerationTypeRevision {
+			continue
+		}
+		stats := ModerationStats{TargetType: typ}
+		for _, p := range pending {
+			if p.TargetType != typ {
+				continue
+			}
+			stats.Pending, stats.Claimed, stats.Overdue = p.Pending, p.Claimed, p.Overdue
+			if p.Oldest != nil {
+				stats.OldestPendingSeconds = int64(now.Sub(*p.Oldest).Seconds())
+			}
+		}
+		// 修订的审核记录挂在博客下，只统计积压
+		if typ == ModerationTypeRevision {
+			report.Types = append(report.Types, stats)
+			continue
+		}
+
+		var reviewed struct {
+			Reviewed   int64
+			AvgSeconds float64
+			P50Seconds float64
+			P90Seconds float64
+			WithinSLA  int64
+		}
+		err := db.WithContext(ctx).Raw(fmt.Sprintf(`SELECT COUNT(*) AS reviewed, COALESCE(AVG(wait), 0) AS avg_seconds,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY wait), 0) AS p50_seconds,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY wait), 0) AS p90_seconds,
+			COUNT(*) FILTER (WHERE wait <= ?) AS within_sla
+			FROM (
+				SELECT EXTRACT(EPOCH FROM d.created_at - COALESCE(
+					(SELECT MAX(r.created_at) FROM content_reviews r
+						WHERE r.target_type = d.target_type AND r.target_id = d.target_id AND r.to_status = ?
+						AND r.created_at < d.created_at AND r.deleted_at IS NULL),
+					t.created_at)) AS wait
+				FROM content_reviews d JOIN %s t ON t.id = d.target_id
+				WHERE d.target_type = ? AND d.from_status = ? AND d.action IN ? AND d.created_at >= ? AND d.deleted_at IS NULL
+			) w`, target.table),
+			sla.Seconds(), PublishStatusSubmitted, typ, PublishStatusSubmitted,
+			[]string{ReviewActionApprove, ReviewActionRequestChanges}, since).
+			Scan(&reviewed).Error
+		if err != nil {
+			return nil, err
+		}
+		stats.Reviewed = reviewed.Reviewed
+		stats.AvgSeconds, stats.P50Seconds, stats.P90Seconds = reviewed.AvgSeconds, reviewed.P50Seconds, reviewed.P90Seconds
+		if reviewed.Reviewed > 0 {
+			stats.WithinSLA = float64(reviewed.WithinSLA) / float64(reviewed.Reviewed)
+		}
+		report.Types = append(report.Types, stats)
+	}
+	return report, nil
+}
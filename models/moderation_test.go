@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 需要 PostgreSQL，见 useTestDB。已发布博客的待审核修订出现在审核队列中，认领后他人不能审核
+func TestModerationQueueRevisions(t *testing.T) {
+	tx := useTestDB(t)
+	ctx := context.Background()
+
+	run := time.Now().UnixNano()
+	var users [3]User
+	for i := range users {
+		users[i] = User{Email: fmt.Sprintf("moderation-%d-%d@example.com", run, i)}
+		if err := tx.Create(&users[i]).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	author, reviewer, other := users[0], users[1], users[2]
+
+	article := Article{Title: "published", PublisherId: author.ID, PublishStatus: PublishStatusPublished}
+	if err := article.Create(ctx); err != nil {
+		t.Fatalf("create article: %v", err)
+	}
+	_, rev, err := saveArticleRevision(ctx, article.ID, author.ID, ArticleContent{Title: "edited"}, nil)
+	if err != nil {
+		t.Fatalf("save revision: %v", err)
+	}
+	if rev.Status != RevisionPending {
+		t.Fatalf("revision status = %s, want %s", rev.Status, RevisionPending)
+	}
+
+	items, _, err := QueryModerationQueue(ctx, ModerationFilter{Types: []string{ModerationTypeRevision}, PageSize: 100})
+	if err != nil {
+		t.Fatalf("QueryModerationQueue: %v", err)
+	}
+	var found *ModerationItem
+	for i := range items {
+		if items[i].TargetId == article.ID {
+			found = &items[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("pending revision of article %d not in queue", article.ID)
+	}
+	if found.RevisionId == nil || *found.RevisionId != rev.ID || found.Title != "edited" || found.AuthorId != author.ID {
+		t.Errorf("queue item = %+v, want revision %d by %d", found, rev.ID, author.ID)
+	}
+
+	if _, err := ClaimContent(ctx, ModerationTypeRevision, article.ID, reviewer.ID); err != nil {
+		t.Fatalf("ClaimContent: %v", err)
+	}
+	if _, err := ReviewArticleRevision(ctx, article.ID, rev.ID, other.ID, false, "no"); !errors.Is(err, ErrClaimedByOther) {
+		t.Errorf("review by other reviewer error = %v, want %v", err, ErrClaimedByOther)
+	}
+	if _, err := ReviewArticleRevision(ctx, article.ID, rev.ID, reviewer.ID, true, ""); err != nil {
+		t.Fatalf("ReviewArticleRevision: %v", err)
+	}
+	if _, err := ClaimContent(ctx, ModerationTypeRevision, article.ID, reviewer.ID); !errors.Is(err, ErrNotPending) {
+		t.Errorf("claim after review error = %v, want %v", err, ErrNotPending)
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"context"
+	"hyperlane/utils"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationFollow                = "follow"                  // 关注了你
+	NotificationPostLike              = "post_like"               // 点赞了你的帖子
+	NotificationPostFavorite          = "post_favorite"           // 收藏了你的帖子
+	NotificationBlogApproved          = "blog_approved"           // 博客审核通过
+	NotificationBlogChangesRequested  = "blog_changes_requested"  // 博客被退回修改
+	NotificationBlogPublished         = "blog_published"          // 博客已发布
+	NotificationEventApproved         = "event_approved"          // 活动审核通过
+	NotificationEventChangesRequested = "event_changes_requested" // 活动被退回修改
+	NotificationEventPublished        = "event_published"         // 活动已发布
+)
+
+var ErrNotificationNotFound = utils.NewAppError(http.StatusNotFound, "NOTIFICATION_NOT_FOUND", "notification not found")
+
+type Notification struct {
+	gorm.Model
+	UserId     uint       `gorm:"index:idx_notification_user;not null" json:"user_id"` // 接收者
+	ActorId    uint       `json:"actor_id"`                                            // 触发者，系统通知为 0
+	Actor      *User      `gorm:"foreignKey:ActorId" json:"actor"`
+	Type       string     `json:"type"`
+	TargetType string     `json:"target_type"` // post / blog / event / user
+	TargetId   uint       `json:"target_id"`
+	Content    string     `json:"content"`
+	ReadAt     *time.Time `gorm:"index:idx_notification_user" json:"read_at"`
+}
+
+// 创建通知，不通知自己；同一触发者对同一目标的未读通知不重复创建
+func createNotification(tx *gorm.DB, n *Notification) error {
+	if n.UserId == 0 || n.UserId == n.ActorId {
+		return nil
+	}
+
+	var count int64
+	err := tx.Model(&Notification{}).
+		Where("user_id = ? AND actor_id = ? AND type = ? AND target_id = ? AND read_at IS NULL",
+			n.UserId, n.ActorId, n.Type, n.TargetId).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return tx.Create(n).Error
+}
+
+func CreateNotification(ctx context.Context, n *Notification) error {
+	return createNotification(db.WithContext(ctx), n)
+}
+
+// 帖子作者收到点赞/收藏通知
+func notifyPostAuthor(tx *gorm.DB, postID, actorID uint, notificationType string) error {
+	var post Post
+	if err := tx.Select("id", "user_id", "title").First(&post, postID).Error; err != nil {
+		return err
+	}
+
+	return createNotification(tx, &Notification{
+		UserId:     post.UserId,
+		ActorId:    actorID,
+		Type:       notificationType,
+		TargetType: "post",
+		TargetId:   post.ID,
+		Content:    post.Title,
+	})
+}
+
+type NotificationFilter struct {
+	UserId     uint
+	UnreadOnly bool
+	Page       int // 当前页码，从 1 开始
+	PageSize   int // 每页数量，建议默认 10
+}
+
+func QueryNotifications(ctx context.Context, filter NotificationFilter) ([]Notification, int64, error) {
+	var notifications []Notification
+	var total int64
+
+	query := db.WithContext(ctx).Preload("Actor").Model(&Notification{}).Where("user_id = ?", filter.UserId)
+
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	query = query.Order("created_at desc")
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.Offset(offset).Limit(filter.PageSize)
+
+	err := query.Find(&notifications).Error
+	return notifications, total, err
+}
+
+func CountUnreadNotifications(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func MarkNotificationRead(ctx context.Context, userID, id uint) error {
+	res := db.WithContext(ctx).Model(&Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var count int64
+		db.WithContext(ctx).Model(&Notification{}).Where("id = ? AND user_id = ?", id, userID).Count(&count)
+		if count == 0 {
+			return ErrNotificationNotFound
+		}
+	}
+	return nil
+}
+
+func MarkAllNotificationsRead(ctx context.Context, userID uint) (int64, error) {
+	res := db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
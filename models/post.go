@@ -1,7 +1,10 @@
 package models
 
 import (
+	"context"
 	"errors"
+	"hyperlane/utils"
+	"net/http"
 	"strings"
 	"time"
 
@@ -9,6 +12,13 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrPostNotFound     = utils.NewAppError(http.StatusNotFound, "POST_NOT_FOUND", "post not found")
+	ErrNotAuthor        = utils.NewAppError(http.StatusForbidden, "NOT_AUTHOR", "only the author can do this")
+	ErrAlreadyLiked     = utils.NewAppError(http.StatusConflict, "ALREADY_LIKED", "already liked")
+	ErrAlreadyFavorited = utils.NewAppError(http.StatusConflict, "ALREADY_FAVORITED", "already favorited")
+)
+
 type Post struct {
 	gorm.Model
 	Title         string         `json:"title"`
@@ -20,31 +30,44 @@ type Post struct {
 	User          *User          `gorm:"foreignKey:UserId" json:"user"`
 	LikeCount     uint           `json:"like_count"`
 	FavoriteCount uint           `json:"favorite_count"`
+	CommentCount  uint           `gorm:"default:0" json:"comment_count"`
+	Score         float64        `gorm:"->" json:"score,omitempty"` // 信息流热度分，只在 QueryFeed 中查询
 }
 
-func (p *Post) Create() error {
-	return db.Create(p).Error
+func (p *Post) Create(ctx context.Context) error {
+	return db.WithContext(ctx).Create(p).Error
 }
 
-func (p *Post) GetByID(id uint) error {
-	if err := db.Preload("User").First(p, id).Error; err != nil {
+func (p *Post) GetByID(ctx context.Context, id uint) error {
+	if err := db.WithContext(ctx).Preload("User").First(p, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPostNotFound
+		}
 		return err
 	}
-	return db.Model(p).Update("view_count", gorm.Expr("view_count + ?", 1)).Error
+	return nil
+}
+
+func (p Post) CursorKey() (time.Time, uint) {
+	return p.CreatedAt, p.ID
 }
 
-func (p *Post) Update() error {
+// 由浏览量刷新、点赞、收藏、评论维护的计数列，编辑时不覆盖
+var postCounterColumns = []string{"view_count", "like_count", "favorite_count", "comment_count"}
+
+// Update 保存帖子，计数列以数据库为准
+func (p *Post) Update(ctx context.Context) error {
 	if p.ID == 0 {
 		return errors.New("missing ID")
 	}
-	return db.Save(p).Error
+	return db.WithContext(ctx).Omit(postCounterColumns...).Save(p).Error
 }
 
-func (p *Post) Delete() error {
+func (p *Post) Delete(ctx context.Context) error {
 	if p.ID == 0 {
 		return errors.New("missing ID")
 	}
-	return db.Delete(p).Error
+	return db.WithContext(ctx).Delete(p).Error
 }
 
 type PostFilter struct {
@@ -56,15 +79,11 @@ type PostFilter struct {
 	Page      int
 	PageSize  int
 	OrderDesc bool
-
-	// ------------------------
-	// Hybrid Feed
-	// ------------------------
-	Hybrid      bool // true 表示混合流模式（关注流 + 自然流）
-	FollowingOf uint // 当前登录用户 ID，用于查询关注流
+	Cursor    *utils.Cursor // 游标分页，非空时忽略 Page 和 OrderDesc，按 (created_at, id) 从游标之后读取
+	SkipTotal bool          // 不统计总数，total 返回 -1
 }
 
-func QueryPosts(filter PostFilter) ([]Post, int64, error) {
+func QueryPosts(ctx context.Context, filter PostFilter) ([]Post, int64, error) {
 	var posts []Post
 	var total int64
 
@@ -78,84 +97,7 @@ func QueryPosts(filter PostFilter) ([]Post, int64, error) {
 		page = 1
 	}
 
-	// 判断是否 hybrid 模式（关注流 + 自然流混合）
-	if filter.Hybrid && filter.FollowingOf != 0 {
-		// 70%关注流，30%自然流
-		followLimit := int(float64(pageSize) * 0.7)
-
-		// 查询关注流
-		var followPosts []Post
-		followQuery := db.Preload("User").Model(&Post{}).Joins("LEFT JOIN users ON users.id = posts.user_id")
-		if filter.Keyword != "" {
-			likePattern := "%" + strings.ToLower(filter.Keyword) + "%"
-			followQuery = followQuery.Where(`
-				LOWER(posts.title) LIKE ? OR
-				LOWER(posts.description) LIKE ? OR
-				LOWER(users.username) LIKE ?
-			`, likePattern, likePattern, likePattern)
-		}
-		if filter.UserId != 0 {
-			followQuery = followQuery.Where("user_id = ?", filter.UserId)
-		}
-		if filter.StartDate != nil {
-			followQuery = followQuery.Where("posts.created_at BETWEEN ? AND ?", filter.StartDate, filter.EndDate)
-		}
-		followQuery = followQuery.Where("posts.user_id IN (?)",
-			db.Model(&Follow{}).Select("following_id").Where("follower_id = ?", filter.FollowingOf),
-		)
-		followQuery = followQuery.Order("created_at desc").Order("view_count desc").Limit(50)
-		if err := followQuery.Find(&followPosts).Error; err != nil {
-			return nil, 0, err
-		}
-
-		// 查询自然流
-		var globalPosts []Post
-		globalQuery := db.Preload("User").Model(&Post{}).Joins("LEFT JOIN users ON users.id = posts.user_id")
-		if filter.Keyword != "" {
-			likePattern := "%" + strings.ToLower(filter.Keyword) + "%"
-			globalQuery = globalQuery.Where(`
-				LOWER(posts.title) LIKE ? OR
-				LOWER(posts.description) LIKE ? OR
-				LOWER(users.username) LIKE ?
-			`, likePattern, likePattern, likePattern)
-		}
-		if filter.UserId != 0 {
-			globalQuery = globalQuery.Where("user_id = ?", filter.UserId)
-		}
-		if filter.StartDate != nil {
-			globalQuery = globalQuery.Where("posts.created_at BETWEEN ? AND ?", filter.StartDate, filter.EndDate)
-		}
-		globalQuery = globalQuery.Where("posts.user_id NOT IN (?)",
-			db.Model(&Follow{}).Select("following_id").Where("follower_id = ?", filter.FollowingOf),
-		)
-		globalQuery = globalQuery.Order("created_at desc").Order("view_count desc").Limit(50)
-		if err := globalQuery.Find(&globalPosts).Error; err != nil {
-			return nil, 0, err
-		}
-
-		// 按比例混合
-		fIdx, gIdx := 0, 0
-		for len(posts) < pageSize {
-			if fIdx < len(followPosts) && len(posts) < followLimit {
-				posts = append(posts, followPosts[fIdx])
-				fIdx++
-			}
-			if gIdx < len(globalPosts) && len(posts) < pageSize {
-				posts = append(posts, globalPosts[gIdx])
-				gIdx++
-			}
-			if fIdx >= len(followPosts) && gIdx >= len(globalPosts) {
-				break
-			}
-		}
-
-		// 统计总数（可选，近似值）
-		total = int64(len(posts))
-		return posts, total, nil
-	}
-
-	// 非 hybrid 模式，原有逻辑
-	query := db.Preload("User").Model(&Post{}).Joins("LEFT JOIN users ON users.id = posts.user_id")
+	query := db.WithContext(ctx).Preload("User").Model(&Post{}).Joins("LEFT JOIN users ON users.id = posts.user_id")
 
 	if filter.Keyword != "" {
 		likePattern := "%" + strings.ToLower(filter.Keyword) + "%"
@@ -167,7 +109,7 @@ func QueryPosts(filter PostFilter) ([]Post, int64, error) {
 	}
 
 	if filter.UserId != 0 {
-		query = query.Where("user_id = ?", filter.UserId)
+		query = query.Where("posts.user_id = ?", filter.UserId)
 	}
 
 	if filter.StartDate != nil {
@@ -175,15 +117,23 @@ func QueryPosts(filter PostFilter) ([]Post, int64, error) {
 	}
 
 	// 统计总数
-	query.Count(&total)
+	total = -1
+	if !filter.SkipTotal {
+		query.Count(&total)
+	}
+
+	if filter.Cursor != nil {
+		err := keysetPage(query, "posts.created_at", "posts.id", filter.Cursor, pageSize).Find(&posts).Error
+		return posts, total, err
+	}
 
-	// 排序
+	// 排序（与 users 联表，需要带表名）
 	if filter.OrderDesc {
-		query = query.Order("created_at desc")
+		query = query.Order("posts.created_at desc")
 	} else {
-		query = query.Order("created_at asc")
+		query = query.Order("posts.created_at asc")
 	}
-	query = query.Order("view_count desc")
+	query = query.Order("posts.view_count desc")
 
 	// 分页
 	offset := (page - 1) * pageSize
@@ -259,7 +209,7 @@ type ActiveUserStat struct {
 	PostCount int64  `json:"post_count"`
 }
 
-func GetPostStats(limit int) (*PostStats, error) {
+func GetPostStats(ctx context.Context, limit int) (*PostStats, error) {
 	var stats PostStats
 	var err error
 
@@ -273,7 +223,7 @@ func GetPostStats(limit int) (*PostStats, error) {
 	}
 
 	var res result
-	err = db.Raw(`
+	err = db.WithContext(ctx).Raw(`
 			SELECT 
 				(SELECT COUNT(*) FROM posts) AS total_posts,
 				(SELECT COUNT(*) FROM posts WHERE created_at >= ?) AS weekly_posts,
@@ -288,7 +238,7 @@ func GetPostStats(limit int) (*PostStats, error) {
 	stats.ActiveUserCount = res.ActiveUsers
 
 	// 获取本周热门帖子
-	err = db.Preload("User").
+	err = db.WithContext(ctx).Preload("User").
 		Where("created_at >= ?", startOfWeek).
 		Order("view_count desc").
 		Limit(limit).
@@ -298,7 +248,7 @@ func GetPostStats(limit int) (*PostStats, error) {
 	}
 
 	// 获取总热门帖子
-	err = db.Preload("User").
+	err = db.WithContext(ctx).Preload("User").
 		Order("view_count desc").
 		Limit(limit).
 		Find(&stats.AllTimeHotPosts).Error
@@ -307,7 +257,7 @@ func GetPostStats(limit int) (*PostStats, error) {
 	}
 
 	// 获取发帖最多的用户列表（活跃用户）
-	err = db.Model(&User{}).
+	err = db.WithContext(ctx).Model(&User{}).
 		Select("users.id, users.email, users.username, users.avatar, COUNT(posts.id) AS post_count").
 		Joins("JOIN posts ON posts.user_id = users.id").
 		Where("posts.deleted_at IS NULL").
@@ -335,8 +285,8 @@ type PostFavorite struct {
 }
 
 // 点赞
-func LikePost(postID, userID uint) error {
-	tx := db.Begin()
+func LikePost(ctx context.Context, postID, userID uint) error {
+	tx := db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -362,12 +312,17 @@ func LikePost(postID, userID uint) error {
 				return err
 			}
 
+			if err = notifyPostAuthor(tx, postID, userID, NotificationPostLike); err != nil {
+				tx.Rollback()
+				return err
+			}
+
 			return tx.Commit().Error
 		}
 
 		// 已点赞
 		tx.Rollback()
-		return errors.New("already liked")
+		return ErrAlreadyLiked
 	}
 
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -391,17 +346,22 @@ func LikePost(postID, userID uint) error {
 		return err
 	}
 
+	if err = notifyPostAuthor(tx, postID, userID, NotificationPostLike); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
 // 取消点赞
-func UnlikePost(postID, userID uint) error {
-	res := db.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&PostLike{})
+func UnlikePost(ctx context.Context, postID, userID uint) error {
+	res := db.WithContext(ctx).Where("post_id = ? AND user_id = ?", postID, userID).Delete(&PostLike{})
 	if res.Error != nil {
 		return res.Error
 	}
 	if res.RowsAffected > 0 {
-		if err := db.Model(&Post{}).
+		if err := db.WithContext(ctx).Model(&Post{}).
 			Where("id = ?", postID).
 			UpdateColumn("like_count", gorm.Expr("like_count - ?", 1)).Error; err != nil {
 			return err
@@ -411,8 +371,8 @@ func UnlikePost(postID, userID uint) error {
 }
 
 // 收藏
-func FavoritePost(postID, userID uint) error {
-	tx := db.Begin()
+func FavoritePost(ctx context.Context, postID, userID uint) error {
+	tx := db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -439,11 +399,16 @@ func FavoritePost(postID, userID uint) error {
 				return err
 			}
 
+			if err = notifyPostAuthor(tx, postID, userID, NotificationPostFavorite); err != nil {
+				tx.Rollback()
+				return err
+			}
+
 			return tx.Commit().Error
 		}
 
 		tx.Rollback()
-		return errors.New("already favorited")
+		return ErrAlreadyFavorited
 	}
 
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -466,19 +431,24 @@ func FavoritePost(postID, userID uint) error {
 		return err
 	}
 
+	if err = notifyPostAuthor(tx, postID, userID, NotificationPostFavorite); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
 // 取消收藏
-func UnfavoritePost(postID, userID uint) error {
-	res := db.Where("post_id = ? AND user_id = ?", postID, userID).
+func UnfavoritePost(ctx context.Context, postID, userID uint) error {
+	res := db.WithContext(ctx).Where("post_id = ? AND user_id = ?", postID, userID).
 		Delete(&PostFavorite{})
 	if res.Error != nil {
 		return res.Error
 	}
 
 	if res.RowsAffected > 0 {
-		if err := db.Model(&Post{}).
+		if err := db.WithContext(ctx).Model(&Post{}).
 			Where("id = ?", postID).
 			UpdateColumn("favorite_count", gorm.Expr("favorite_count - ?", 1)).Error; err != nil {
 			return err
@@ -494,16 +464,16 @@ type PostStatus struct {
 }
 
 // 获取用户对一组帖子ID的点赞和收藏状态
-func GetUserPostStatuses(userID uint, postIDs []uint) (PostStatus, error) {
+func GetUserPostStatuses(ctx context.Context, userID uint, postIDs []uint) (PostStatus, error) {
 	var likedPostIDs []uint
-	if err := db.Model(&PostLike{}).
+	if err := db.WithContext(ctx).Model(&PostLike{}).
 		Where("user_id = ? AND post_id IN ?", userID, postIDs).
 		Pluck("post_id", &likedPostIDs).Error; err != nil {
 		return PostStatus{}, err
 	}
 
 	var favoritedPostIDs []uint
-	if err := db.Model(&PostFavorite{}).
+	if err := db.WithContext(ctx).Model(&PostFavorite{}).
 		Where("user_id = ? AND post_id IN ?", userID, postIDs).
 		Pluck("post_id", &favoritedPostIDs).Error; err != nil {
 		return PostStatus{}, err
@@ -517,10 +487,10 @@ func GetUserPostStatuses(userID uint, postIDs []uint) (PostStatus, error) {
 }
 
 // 查询用户是否关注了这些帖子作者
-func GetUserFollowStatusForPosts(userID uint, postIDs []uint) ([]uint, error) {
+func GetUserFollowStatusForPosts(ctx context.Context, userID uint, postIDs []uint) ([]uint, error) {
 	// 查出作者ID
 	var authorIDs []uint
-	if err := db.Model(&Post{}).
+	if err := db.WithContext(ctx).Model(&Post{}).
 		Where("id IN ?", postIDs).
 		Pluck("user_id", &authorIDs).Error; err != nil {
 		return nil, err
@@ -539,7 +509,7 @@ func GetUserFollowStatusForPosts(userID uint, postIDs []uint) ([]uint, error) {
 	// 查询是否关注
 	var following []uint
 	if len(uniqueAuthors) > 0 {
-		if err := db.Model(&Follow{}).
+		if err := db.WithContext(ctx).Model(&Follow{}).
 			Where("follower_id = ? AND following_id IN ?", userID, uniqueAuthors).
 			Pluck("following_id", &following).Error; err != nil {
 			return nil, err
@@ -0,0 +1,344 @@
+package models
+
+import (
+	"context"
+	"hyperlane/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditTargetRole            = "role"
+	AuditTargetPermissionGroup = "permission_group"
+	AuditTargetUser            = "user"
+)
+
+var (
+	ErrRoleNotFound            = utils.NewAppError(http.StatusNotFound, "ROLE_NOT_FOUND", "role not found")
+	ErrRoleExists              = utils.NewAppError(http.StatusConflict, "ROLE_EXISTS", "role already exists")
+	ErrPermissionNotFound      = utils.NewAppError(http.StatusNotFound, "PERMISSION_NOT_FOUND", "permission not found")
+	ErrPermissionGroupNotFound = utils.NewAppError(http.StatusNotFound, "PERMISSION_GROUP_NOT_FOUND", "permission group not found")
+	ErrPermissionGroupExists   = utils.NewAppError(http.StatusConflict, "PERMISSION_GROUP_EXISTS", "permission group already exists")
+	ErrUserNotFound            = utils.NewAppError(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+	ErrLastRBACManager         = utils.NewAppError(http.StatusConflict, "LAST_RBAC_MANAGER", "at least one user must keep rbac:manage")
+)
+
+// 初始化之后新增的权限，已有数据库启动时补齐并授予超级管理员权限组
+var extraPermissions = []Permission{
+	{Name: "rbac:manage", Description: "管理角色与权限"},
+}
+
+func upgradeRolesAndPermissions(ctx context.Context) error {
+	var superAdmin PermissionGroup
+	if err := db.WithContext(ctx).Where("name = ?", "超级管理员").First(&superAdmin).Error; err != nil {
+		return err
+	}
+
+	for _, p := range extraPermissions {
+		perm := p
+		if err := db.WithContext(ctx).Where(Permission{Name: p.Name}).Attrs(Permission{Description: p.Description}).FirstOrCreate(&perm).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		err := db.WithContext(ctx).Table("permission_group_permissions").
+			Where("permission_group_id = ? AND permission_id = ?", superAdmin.ID, perm.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := db.WithContext(ctx).Model(&superAdmin).Association("Permissions").Append(&perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ListPermissions(ctx context.Context) ([]Permission, error) {
+	var perms []Permission
+	err := db.WithContext(ctx).Order("id asc").Find(&perms).Error
+	return perms, err
+}
+
+func ListRoles(ctx context.Context) ([]Role, error) {
+	var roles []Role
+	err := db.WithContext(ctx).Preload("Permissions").
+		Preload("PermissionGroups").
+		Order("id asc").
+		Find(&roles).Error
+	return roles, err
+}
+
+func ListPermissionGroups(ctx context.Context) ([]PermissionGroup, error) {
+	var groups []PermissionGroup
+	err := db.WithContext(ctx).Preload("Permissions").Order("id asc").Find(&groups).Error
+	return groups, err
+}
+
+func CreateRole(ctx context.Context, actorId uint, role *Role) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Role{}).Where("name = ?", role.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRoleExists
+		}
+
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, actorId, "role.create", AuditTargetRole, role.ID, role)
+	})
+}
+
+func UpdateRole(ctx context.Context, actorId uint, id uint, name, description string) (*Role, error) {
+	var role Role
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&role, id).Error; err != nil {
+			return ErrRoleNotFound
+		}
+
+		var count int64
+		if err := tx.Model(&Role{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRoleExists
+		}
+
+		before := map[string]string{"name": role.Name, "description": role.Description}
+		role.Name = name
+		role.Description = description
+		if err := tx.Save(&role).Error; err != nil {
+			return err
+		}
+
+		after := map[string]string{"name": role.Name, "description": role.Description}
+		return writeAuditLog(tx, actorId, "role.update", AuditTargetRole, role.ID,
+			map[string]interface{}{"before": before, "after": after})
+	})
+	return &role, err
+}
+
+func CreatePermissionGroup(ctx context.Context, actorId uint, group *PermissionGroup) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&PermissionGroup{}).Where("name = ?", group.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPermissionGroupExists
+		}
+
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, actorId, "permission_group.create", AuditTargetPermissionGroup, group.ID, group)
+	})
+}
+
+func UpdatePermissionGroup(ctx context.Context, actorId uint, id uint, name, description string) (*PermissionGroup, error) {
+	var group PermissionGroup
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&group, id).Error; err != nil {
+			return ErrPermissionGroupNotFound
+		}
+
+		var count int64
+		if err := tx.Model(&PermissionGroup{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPermissionGroupExists
+		}
+
+		before := map[string]string{"name": group.Name, "description": group.Description}
+		group.Name = name
+		group.Description = description
+		if err := tx.Save(&group).Error; err != nil {
+			return err
+		}
+
+		after := map[string]string{"name": group.Name, "description": group.Description}
+		return writeAuditLog(tx, actorId, "permission_group.update", AuditTargetPermissionGroup, group.ID,
+			map[string]interface{}{"before": before, "after": after})
+	})
+	return &group, err
+}
+
+// 关联表中是否已存在该关联，用于跳过没有变化的关联/取消关联
+func hasLink(tx *gorm.DB, table, ownerColumn string, ownerId uint, memberColumn string, memberId uint) (bool, error) {
+	var count int64
+	err := tx.Table(table).Where(ownerColumn+" = ? AND "+memberColumn+" = ?", ownerId, memberId).Count(&count).Error
+	return count > 0, err
+}
+
+// 取消关联或调整角色后，确认仍有用户持有 rbac:manage，否则回滚，避免所有人都无法再管理权限。
+// 先取事务级咨询锁，并发的权限调整依次检查，不会各自以为还有其他管理员
+func ensureRBACManager(tx *gorm.DB) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('rbac:manage'))").Error; err != nil {
+		return err
+	}
+	var exists bool
+	err := tx.Raw(`SELECT EXISTS (
+		SELECT 1 FROM users u
+		WHERE u.deleted_at IS NULL AND u.role_id IN (
+			SELECT rp.role_id FROM role_permissions rp
+				JOIN permissions p ON p.id = rp.permission_id
+				WHERE p.name = ?
+			UNION
+			SELECT rg.role_id FROM role_permission_groups rg
+				JOIN permission_group_permissions gp ON gp.permission_group_id = rg.permission_group_id
+				JOIN permissions p ON p.id = gp.permission_id
+				WHERE p.name = ?
+		)
+	)`, "rbac:manage", "rbac:manage").Scan(&exists).Error
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrLastRBACManager
+	}
+	return nil
+}
+
+// 角色直接关联/取消关联权限，关联没有变化时不做任何写入
+func SetRolePermission(ctx context.Context, actorId, roleId, permissionId uint, attach bool) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role Role
+		if err := tx.First(&role, roleId).Error; err != nil {
+			return ErrRoleNotFound
+		}
+		var perm Permission
+		if err := tx.First(&perm, permissionId).Error; err != nil {
+			return ErrPermissionNotFound
+		}
+
+		linked, err := hasLink(tx, "role_permissions", "role_id", role.ID, "permission_id", perm.ID)
+		if err != nil || linked == attach {
+			return err
+		}
+
+		action := "role.permission.attach"
+		assoc := tx.Model(&role).Association("Permissions")
+		if attach {
+			err = assoc.Append(&perm)
+		} else {
+			action = "role.permission.detach"
+			if err = assoc.Delete(&perm); err == nil {
+				err = ensureRBACManager(tx)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, actorId, action, AuditTargetRole, role.ID,
+			map[string]interface{}{"permission_id": perm.ID, "permission": perm.Name})
+	})
+}
+
+// 角色关联/取消关联权限组，关联没有变化时不做任何写入
+func SetRolePermissionGroup(ctx context.Context, actorId, roleId, groupId uint, attach bool) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role Role
+		if err := tx.First(&role, roleId).Error; err != nil {
+			return ErrRoleNotFound
+		}
+		var group PermissionGroup
+		if err := tx.First(&group, groupId).Error; err != nil {
+			return ErrPermissionGroupNotFound
+		}
+
+		linked, err := hasLink(tx, "role_permission_groups", "role_id", role.ID, "permission_group_id", group.ID)
+		if err != nil || linked == attach {
+			return err
+		}
+
+		action := "role.group.attach"
+		assoc := tx.Model(&role).Association("PermissionGroups")
+		if attach {
+			err = assoc.Append(&group)
+		} else {
+			action = "role.group.detach"
+			if err = assoc.Delete(&group); err == nil {
+				err = ensureRBACManager(tx)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, actorId, action, AuditTargetRole, role.ID,
+			map[string]interface{}{"permission_group_id": group.ID, "permission_group": group.Name})
+	})
+}
+
+// 权限组关联/取消关联权限，关联没有变化时不做任何写入
+func SetPermissionGroupPermission(ctx context.Context, actorId, groupId, permissionId uint, attach bool) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group PermissionGroup
+		if err := tx.First(&group, groupId).Error; err != nil {
+			return ErrPermissionGroupNotFound
+		}
+		var perm Permission
+		if err := tx.First(&perm, permissionId).Error; err != nil {
+			return ErrPermissionNotFound
+		}
+
+		linked, err := hasLink(tx, "permission_group_permissions", "permission_group_id", group.ID, "permission_id", perm.ID)
+		if err != nil || linked == attach {
+			return err
+		}
+
+		action := "permission_group.permission.attach"
+		assoc := tx.Model(&group).Association("Permissions")
+		if attach {
+			err = assoc.Append(&perm)
+		} else {
+			action = "permission_group.permission.detach"
+			if err = assoc.Delete(&perm); err == nil {
+				err = ensureRBACManager(tx)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, actorId, action, AuditTargetPermissionGroup, group.ID,
+			map[string]interface{}{"permission_id": perm.ID, "permission": perm.Name})
+	})
+}
+
+// 给用户分配角色，用户旧 token 会因权限变化在 JWT 中间件处失效；角色未变时不做任何写入
+func AssignUserRole(ctx context.Context, actorId, userId, roleId uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return ErrUserNotFound
+		}
+		var role Role
+		if err := tx.First(&role, roleId).Error; err != nil {
+			return ErrRoleNotFound
+		}
+
+		before := user.RoleID
+		if before == role.ID {
+			return nil
+		}
+		if err := tx.Model(&user).Update("role_id", role.ID).Error; err != nil {
+			return err
+		}
+		if err := ensureRBACManager(tx); err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, actorId, "user.role.assign", AuditTargetUser, user.ID,
+			map[string]interface{}{"before_role_id": before, "role_id": role.ID, "role": role.Name})
+	})
+}
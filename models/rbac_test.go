@@ -0,0 +1,73 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 需要 PostgreSQL，见 useTestDB。没有变化的关联不写审计日志，不能移除最后一个 rbac:manage
+func TestSetRolePermissionGuards(t *testing.T) {
+	tx := useTestDB(t)
+	ctx := context.Background()
+
+	// 只保留本测试创建的管理员，事务结束时回滚
+	for _, table := range []string{"role_permissions", "permission_group_permissions"} {
+		err := tx.Exec("DELETE FROM "+table+" WHERE permission_id IN (SELECT id FROM permissions WHERE name = ?)", "rbac:manage").Error
+		if err != nil {
+			t.Fatalf("clear %s: %v", table, err)
+		}
+	}
+
+	run := time.Now().UnixNano()
+	var manage Permission
+	if err := tx.Where(Permission{Name: "rbac:manage"}).FirstOrCreate(&manage).Error; err != nil {
+		t.Fatalf("create permission: %v", err)
+	}
+	role := Role{Name: fmt.Sprintf("rbac-test-%d", run)}
+	if err := tx.Create(&role).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	admin := User{Email: fmt.Sprintf("rbac-%d@example.com", run), RoleID: role.ID}
+	if err := tx.Create(&admin).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	auditCount := func() int64 {
+		t.Helper()
+		var n int64
+		if err := tx.Model(&AuditLog{}).Where("target_type = ? AND target_id = ?", AuditTargetRole, role.ID).Count(&n).Error; err != nil {
+			t.Fatalf("count audit logs: %v", err)
+		}
+		return n
+	}
+
+	tests := []struct {
+		name      string
+		attach    bool
+		wantErr   error
+		wantAudit int64
+	}{
+		{name: "Attach", attach: true, wantAudit: 1},
+		{name: "Attach again is a no-op", attach: true, wantAudit: 1},
+		{name: "Detach last manager", attach: false, wantErr: ErrLastRBACManager, wantAudit: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetRolePermission(ctx, admin.ID, role.ID, manage.ID, tt.attach)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetRolePermission() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := auditCount(); got != tt.wantAudit {
+				t.Errorf("audit logs = %d, want %d", got, tt.wantAudit)
+			}
+		})
+	}
+
+	linked, err := hasLink(tx, "role_permissions", "role_id", role.ID, "permission_id", manage.ID)
+	if err != nil || !linked {
+		t.Errorf("rbac:manage still linked = %v (%v), want true", linked, err)
+	}
+}
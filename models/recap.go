@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"errors"
 
 	"gorm.io/gorm"
@@ -18,28 +19,28 @@ type Recap struct {
 	User      *User  `gorm:"foreignKey:UserId" json:"user"`
 }
 
-func (r *Recap) Create() error {
-	return db.Create(r).Error
+func (r *Recap) Create(ctx context.Context) error {
+	return db.WithContext(ctx).Create(r).Error
 }
 
-func (r *Recap) GetByID(id uint) error {
-	return db.Preload("User").First(r, id).Error
+func (r *Recap) GetByID(ctx context.Context, id uint) error {
+	return db.WithContext(ctx).Preload("User").First(r, id).Error
 }
 
-func (r *Recap) GetByEventId(eventId uint) error {
-	return db.Preload("User").Where("event_id = ?", eventId).First(r).Error
+func (r *Recap) GetByEventId(ctx context.Context, eventId uint) error {
+	return db.WithContext(ctx).Preload("User").Where("event_id = ?", eventId).First(r).Error
 }
 
-func (r *Recap) Update() error {
+func (r *Recap) Update(ctx context.Context) error {
 	if r.ID == 0 {
 		return errors.New("missing ID")
 	}
-	return db.Save(r).Error
+	return db.WithContext(ctx).Save(r).Error
 }
 
-func (r *Recap) Delete() error {
+func (r *Recap) Delete(ctx context.Context) error {
 	if r.ID == 0 {
 		return errors.New("missing ID")
 	}
-	return db.Delete(r).Error
+	return db.WithContext(ctx).Delete(r).Error
 }
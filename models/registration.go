@@ -0,0 +1,223 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"hyperlane/utils"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RegistrationStatusRegistered = 1 // 已报名
+	RegistrationStatusWaitlisted = 2 // 候补
+	RegistrationStatusCancelled  = 3 // 已取消
+)
+
+var (
+	ErrEventNotFound             = utils.NewAppError(http.StatusNotFound, "EVENT_NOT_FOUND", "event not found")
+	ErrRegistrationClosed        = utils.NewAppError(http.StatusConflict, "REGISTRATION_CLOSED", "registration closed")
+	ErrAlreadyRegistered         = utils.NewAppError(http.StatusConflict, "ALREADY_REGISTERED", "already registered")
+	ErrRegistrationNotFound      = utils.NewAppError(http.StatusNotFound, "REGISTRATION_NOT_FOUND", "registration not found")
+	ErrRegistrationCancelled     = utils.NewAppError(http.StatusConflict, "REGISTRATION_CANCELLED", "registration already cancelled")
+	ErrCapacityBelowParticipants = utils.NewAppError(http.StatusConflict, "CAPACITY_BELOW_PARTICIPANTS", "capacity is below current participants")
+)
+
+type EventRegistration struct {
+	gorm.Model
+	EventId      uint      `gorm:"uniqueIndex:idx_event_user;not null" json:"event_id"`
+	UserId       uint      `gorm:"uniqueIndex:idx_event_user;not null" json:"user_id"`
+	User         *User     `gorm:"foreignKey:UserId" json:"user"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Status       uint      `gorm:"index" json:"status"` // 1: 已报名 2: 候补 3: 已取消
+	RegisteredAt time.Time `json:"registered_at"`       // 最近一次报名时间，候补按此排序
+}
+
+// 报名活动：名额已满时进入候补，参与人数在事务内维护
+func RegisterEvent(ctx context.Context, reg *EventRegistration) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event Event
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, reg.EventId).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEventNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if event.PublishStatus != 2 || !now.Before(event.EndTime) {
+			return ErrRegistrationClosed
+		}
+		if event.RegistrationDeadline != nil && now.After(*event.RegistrationDeadline) {
+			return ErrRegistrationClosed
+		}
+
+		status := uint(RegistrationStatusRegistered)
+		if event.Capacity > 0 && event.Participants >= event.Capacity {
+			status = RegistrationStatusWaitlisted
+		}
+
+		var existing EventRegistration
+		err = tx.Where("event_id = ? AND user_id = ?", reg.EventId, reg.UserId).First(&existing).Error
+		if err == nil {
+			if existing.Status != RegistrationStatusCancelled {
+				return ErrAlreadyRegistered
+			}
+
+			// 重新报名
+			existing.Name = reg.Name
+			existing.Email = reg.Email
+			existing.Status = status
+			existing.RegisteredAt = now
+			if err := tx.Save(&existing).Error; err != nil {
+				return err
+			}
+			*reg = existing
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			reg.Status = status
+			reg.RegisteredAt = now
+			if err := tx.Create(reg).Error; err != nil {
+				return err
+			}
+		} else {
+			return err
+		}
+
+		if status != RegistrationStatusRegistered {
+			return nil
+		}
+		return tx.Model(&Event{}).
+			Where("id = ?", reg.EventId).
+			UpdateColumn("participants", gorm.Expr("participants + ?", 1)).Error
+	})
+}
+
+// 取消报名：释放名额并按报名时间递补第一位候补
+func CancelEventRegistration(ctx context.Context, eventId, userId uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event Event
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventId).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEventNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var reg EventRegistration
+		err = tx.Where("event_id = ? AND user_id = ?", eventId, userId).First(&reg).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRegistrationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if reg.Status == RegistrationStatusCancelled {
+			return ErrRegistrationCancelled
+		}
+
+		wasRegistered := reg.Status == RegistrationStatusRegistered
+		if err := tx.Model(&reg).Update("status", RegistrationStatusCancelled).Error; err != nil {
+			return err
+		}
+		if !wasRegistered {
+			return nil
+		}
+
+		var next EventRegistration
+		err = tx.Where("event_id = ? AND status = ?", eventId, RegistrationStatusWaitlisted).
+			Order("registered_at asc").
+			First(&next).Error
+		if err == nil {
+			// 名额转给候补，参与人数不变
+			return tx.Model(&next).Update("status", RegistrationStatusRegistered).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return tx.Model(&Event{}).
+			Where("id = ?", eventId).
+			UpdateColumn("participants", gorm.Expr("GREATEST(participants - ?, 0)", 1)).Error
+	})
+}
+
+// 按报名时间把候补递补到空余名额（名额为 0 表示不限，全部递补），调用方需已锁住活动行
+func promoteWaitlist(tx *gorm.DB, event *Event) error {
+	query := tx.Model(&EventRegistration{}).
+		Where("event_id = ? AND status = ?", event.ID, RegistrationStatusWaitlisted).
+		Order("registered_at asc")
+	if event.Capacity > 0 {
+		if event.Participants >= event.Capacity {
+			return nil
+		}
+		query = query.Limit(int(event.Capacity - event.Participants))
+	}
+
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := tx.Model(&EventRegistration{}).
+		Where("id IN ?", ids).
+		Update("status", RegistrationStatusRegistered).Error
+	if err != nil {
+		return err
+	}
+	event.Participants += uint(len(ids))
+	return tx.Model(&Event{}).
+		Where("id = ?", event.ID).
+		UpdateColumn("participants", event.Participants).Error
+}
+
+func GetEventRegistration(ctx context.Context, eventId, userId uint) (*EventRegistration, error) {
+	var reg EventRegistration
+	if err := db.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventId, userId).First(&reg).Error; err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+type RegistrationFilter struct {
+	EventId  uint
+	Status   int // 0: 全部
+	Page     int // 当前页码，从 1 开始，0 表示不分页（导出）
+	PageSize int
+}
+
+func QueryEventRegistrations(ctx context.Context, filter RegistrationFilter) ([]EventRegistration, int64, error) {
+	var regs []EventRegistration
+	var total int64
+
+	query := db.WithContext(ctx).Preload("User").Model(&EventRegistration{}).Where("event_id = ?", filter.EventId)
+
+	if filter.Status != 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	query = query.Order("registered_at asc")
+
+	// 分页
+	if filter.Page > 0 {
+		if filter.PageSize <= 0 {
+			filter.PageSize = 10
+		}
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+
+	err := query.Find(&regs).Error
+	return regs, total, err
+}
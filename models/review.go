@@ -0,0 +1,292 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hyperlane/utils"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 博客与活动的发布状态，1、2 沿用原有取值
+const (
+	PublishStatusSubmitted        uint = 1 // 待审核
+	PublishStatusPublished        uint = 2 // 已发布
+	PublishStatusDraft            uint = 3 // 草稿
+	PublishStatusChangesRequested uint = 4 // 退回修改
+	PublishStatusApproved         uint = 5 // 审核通过，待发布
+	PublishStatusUnpublished      uint = 6 // 已下线
+	PublishStatusArchived         uint = 7 // 已归档
+)
+
+// 审核流转动作
+const (
+	ReviewActionSubmit         = "submit"          // 作者提交审核
+	ReviewActionWithdraw       = "withdraw"        // 作者撤回为草稿
+	ReviewActionRequestChanges = "request_changes" // 审核退回，必须填写意见
+	ReviewActionApprove        = "approve"         // 审核通过
+	ReviewActionPublish        = "publish"         // 发布（或重新上线）
+	ReviewActionUnpublish      = "unpublish"       // 下线
+	ReviewActionArchive        = "archive"         // 归档
+
+	// 已发布博客的修订审核，不改变发布状态，只记录在审核历史中
+	ReviewActionApproveRevision = "approve_revision"
+	ReviewActionRejectRevision  = "reject_revision"
+)
+
+// 执行动作需要的角色：作者本人、审核权限（<type>:review）、发布权限（<type>:publish）
+const (
+	ReviewRoleAuthor  = "author"
+	ReviewRoleReview  = "review"
+	ReviewRolePublish = "publish"
+)
+
+var (
+	ErrReviewTargetNotFound = utils.NewAppError(http.StatusNotFound, "CONTENT_NOT_FOUND", "content not found")
+	ErrInvalidReviewAction  = utils.NewAppError(http.StatusBadRequest, "INVALID_REVIEW_ACTION", "invalid review action")
+	ErrInvalidTransition    = utils.NewAppError(http.StatusConflict, "INVALID_TRANSITION", "action not allowed in current status")
+	ErrReviewForbidden      = utils.NewAppError(http.StatusForbidden, "REVIEW_FORBIDDEN", "no permission for this action")
+	ErrReviewNoteRequired   = utils.NewAppError(http.StatusBadRequest, "REVIEW_NOTE_REQUIRED", "note is required when requesting changes")
+)
+
+type reviewTransition struct {
+	from []uint
+	to   uint
+	role string
+}
+
+// 状态机：动作 -> 允许的起始状态、目标状态及所需角色
+var reviewTransitions = map[string]reviewTransition{
+	ReviewActionSubmit: {
+		from: []uint{PublishStatusDraft, PublishStatusChangesRequested, PublishStatusUnpublished},
+		to:   PublishStatusSubmitted,
+		role: ReviewRoleAuthor,
+	},
+	ReviewActionWithdraw: {
+		from: []uint{PublishStatusSubmitted, PublishStatusChangesRequested},
+		to:   PublishStatusDraft,
+		role: ReviewRoleAuthor,
+	},
+	ReviewActionRequestChanges: {
+		from: []uint{PublishStatusSubmitted, PublishStatusApproved},
+		to:   PublishStatusChangesRequested,
+		role: ReviewRoleReview,
+	},
+	ReviewActionApprove: {
+		from: []uint{PublishStatusSubmitted},
+		to:   PublishStatusApproved,
+		role: ReviewRoleReview,
+	},
+	ReviewActionPublish: {
+		from: []uint{PublishStatusApproved, PublishStatusUnpublished},
+		to:   PublishStatusPublished,
+		role: ReviewRolePublish,
+	},
+	ReviewActionUnpublish: {
+		from: []uint{PublishStatusPublished},
+		to:   PublishStatusUnpublished,
+		role: ReviewRolePublish,
+	},
+	ReviewActionArchive: {
+		from: []uint{PublishStatusDraft, PublishStatusChangesRequested, PublishStatusUnpublished, PublishStatusPublished},
+		to:   PublishStatusArchived,
+		role: ReviewRolePublish,
+	},
+}
+
+// 参与审核流程的内容类型：表名及作者字段
+var reviewTargets = map[string]struct {
+	table string
+	owner string
+}{
+	CommentTargetBlog:  {"articles", "publisher_id"},
+	CommentTargetEvent: {"events", "user_id"},
+}
+
+// 审核历史，每次状态流转记录一条
+type ContentReview struct {
+	gorm.Model
+	TargetType string `gorm:"index:idx_review_target;not null" json:"target_type"` // blog / event
+	TargetId   uint   `gorm:"index:idx_review_target;not null" json:"target_id"`
+	ReviewerId uint   `json:"reviewer_id"` // 操作人
+	Reviewer   *User  `gorm:"foreignKey:ReviewerId" json:"reviewer"`
+	Action     string `json:"action"`
+	FromStatus uint   `json:"from_status"`
+	ToStatus   uint   `json:"to_status"`
+	Note       string `gorm:"type:text" json:"note"` // 审核意见
+}
+
+// ReviewActor 执行流转的用户及其拥有的审核、发布权限
+type ReviewActor struct {
+	UserId     uint
+	CanReview  bool
+	CanPublish bool
+}
+
+// 流转后通知作者的类型
+var reviewNotifications = map[string]map[string]string{
+	CommentTargetBlog: {
+		ReviewActionRequestChanges: NotificationBlogChangesRequested,
+		ReviewActionApprove:        NotificationBlogApproved,
+		ReviewActionPublish:        NotificationBlogPublished,
+	},
+	CommentTargetEvent: {
+		ReviewActionRequestChanges: NotificationEventChangesRequested,
+		ReviewActionApprove:        NotificationEventApproved,
+		ReviewActionPublish:        NotificationEventPublished,
+	},
+}
+
+// TransitionContent 锁定内容行，按状态机校验动作后更新发布状态、记录审核历史并通知作者
+func TransitionContent(ctx context.Context, targetType string, id uint, actor ReviewActor, action, note string) (*ContentReview, error) {
+	target, ok := reviewTargets[targetType]
+	if !ok {
+		return nil, ErrReviewTargetNotFound
+	}
+	if _, ok := reviewTransitions[action]; !ok {
+		return nil, ErrInvalidReviewAction
+	}
+	if action == ReviewActionRequestChanges && note == "" {
+		return nil, ErrReviewNoteRequired
+	}
+
+	var review ContentReview
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var row struct {
+			PublishStatus uint
+			OwnerId       uint
+			Title         string
+		}
+		err := tx.Table(target.table).
+			Select(fmt.Sprintf("publish_status, %s AS owner_id, title", target.owner)).
+			Where("id = ? AND deleted_at IS NULL", id).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Take(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReviewTargetNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		to, err := validateTransition(action, row.PublishStatus, actor, row.OwnerId)
+		if err != nil {
+			return err
+		}
+		if reviewTransitions[action].role == ReviewRoleReview {
+			if err := checkClaim(tx, targetType, id, actor.UserId); err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{"publish_status": to, "updated_at": time.Now()}
+		if to == PublishStatusPublished {
+			updates["publish_time"] = time.Now()
+		}
+		if err := tx.Table(target.table).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		// 离开待审核后认领失效
+		if to != PublishStatusSubmitted {
+			if err := tx.Where("target_type = ? AND target_id = ?", targetType, id).Delete(&ModerationClaim{}).Error; err != nil {
+				return err
+			}
+		}
+
+		review = ContentReview{
+			TargetType: targetType,
+			TargetId:   id,
+			ReviewerId: actor.UserId,
+			Action:     action,
+			FromStatus: row.PublishStatus,
+			ToStatus:   to,
+			Note:       note,
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+
+		notificationType, ok := reviewNotifications[targetType][action]
+		if !ok {
+			return nil
+		}
+		content := row.Title
+		if note != "" {
+			content = row.Title + ": " + note
+		}
+		return createNotification(tx, &Notification{
+			UserId:     row.OwnerId,
+			ActorId:    actor.UserId,
+			Type:       notificationType,
+			TargetType: targetType,
+			TargetId:   id,
+			Content:    content,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// 校验动作在当前状态下是否允许、操作人是否有对应角色，返回目标状态
+func validateTransition(action string, from uint, actor ReviewActor, ownerId uint) (uint, error) {
+	transition, ok := reviewTransitions[action]
+	if !ok {
+		return 0, ErrInvalidReviewAction
+	}
+
+	allowed := false
+	switch transition.role {
+	case ReviewRoleAuthor:
+		allowed = actor.UserId == ownerId
+	case ReviewRoleReview:
+		allowed = actor.CanReview
+	case ReviewRolePublish:
+		allowed = actor.CanPublish
+	}
+	if !allowed {
+		return 0, ErrReviewForbidden
+	}
+
+	for _, s := range transition.from {
+		if s == from {
+			return transition.to, nil
+		}
+	}
+	return 0, ErrInvalidTransition
+}
+
+// ReviewOwner 返回内容作者，用于判断是否可以查看审核历史
+func ReviewOwner(ctx context.Context, targetType string, id uint) (uint, error) {
+	target, ok := reviewTargets[targetType]
+	if !ok {
+		return 0, ErrReviewTargetNotFound
+	}
+
+	var ownerId uint
+	res := db.WithContext(ctx).Table(target.table).
+		Select(target.owner).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Scan(&ownerId)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrReviewTargetNotFound
+	}
+	return ownerId, nil
+}
+
+func QueryContentReviews(ctx context.Context, targetType string, targetId uint) ([]ContentReview, error) {
+	var reviews []ContentReview
+	err := db.WithContext(ctx).Preload("Reviewer").
+		Where("target_type = ? AND target_id = ?", targetType, targetId).
+		Order("created_at asc").
+		Find(&reviews).Error
+	return reviews, err
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	author := ReviewActor{UserId: 1}
+	reviewer := ReviewActor{UserId: 2, CanReview: true}
+	publisher := ReviewActor{UserId: 3, CanPublish: true}
+
+	tests := []struct {
+		name    string
+		action  string
+		from    uint
+		actor   ReviewActor
+		want    uint
+		wantErr error
+	}{
+		{name: "Author submits draft", action: ReviewActionSubmit, from: PublishStatusDraft, actor: author, want: PublishStatusSubmitted},
+		{name: "Author resubmits after changes", action: ReviewActionSubmit, from: PublishStatusChangesRequested, actor: author, want: PublishStatusSubmitted},
+		{name: "Other user cannot submit", action: ReviewActionSubmit, from: PublishStatusDraft, actor: reviewer, wantErr: ErrReviewForbidden},
+		{name: "Reviewer approves", action: ReviewActionApprove, from: PublishStatusSubmitted, actor: reviewer, want: PublishStatusApproved},
+		{name: "Reviewer requests changes", action: ReviewActionRequestChanges, from: PublishStatusSubmitted, actor: reviewer, want: PublishStatusChangesRequested},
+		{name: "Reviewer cannot publish", action: ReviewActionPublish, from: PublishStatusApproved, actor: reviewer, wantErr: ErrReviewForbidden},
+		{name: "Publisher cannot approve", action: ReviewActionApprove, from: PublishStatusSubmitted, actor: publisher, wantErr: ErrReviewForbidden},
+		{name: "Publish approved", action: ReviewActionPublish, from: PublishStatusApproved, actor: publisher, want: PublishStatusPublished},
+		{name: "Publish skips review", action: ReviewActionPublish, from: PublishStatusSubmitted, actor: publisher, wantErr: ErrInvalidTransition},
+		{name: "Unpublish", action: ReviewActionUnpublish, from: PublishStatusPublished, actor: publisher, want: PublishStatusUnpublished},
+		{name: "Archived is final", action: ReviewActionPublish, from: PublishStatusArchived, actor: publisher, wantErr: ErrInvalidTransition},
+		{name: "Unknown action", action: "delete", from: PublishStatusDraft, actor: publisher, wantErr: ErrInvalidReviewAction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateTransition(tt.action, tt.from, tt.actor, author.UserId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("validateTransition() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("validateTransition() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"hyperlane/utils"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RevisionPending    = "pending"    // 已发布博客的修改，等待审核，未上线
+	RevisionApplied    = "applied"    // 内容已写入博客
+	RevisionRejected   = "rejected"   // 审核退回
+	RevisionSuperseded = "superseded" // 审核前被更新的修改替代
+)
+
+var (
+	ErrArticleNotFound     = utils.NewAppError(http.StatusNotFound, "ARTICLE_NOT_FOUND", "article not found")
+	ErrArticleArchived     = utils.NewAppError(http.StatusConflict, "ARTICLE_ARCHIVED", "article archived")
+	ErrRevisionNotFound    = utils.NewAppError(http.StatusNotFound, "REVISION_NOT_FOUND", "revision not found")
+	ErrRevisionNotPending  = utils.NewAppError(http.StatusConflict, "REVISION_NOT_PENDING", "revision is not pending review")
+	ErrRevisionNoteMissing = utils.NewAppError(http.StatusBadRequest, "REVISION_NOTE_REQUIRED", "note is required when rejecting a revision")
+)
+
+// ArticleContent 博客中随修订变化的内容字段
+type ArticleContent struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Content     string         `gorm:"type:text" json:"content"`
+	SourceLink  string         `json:"source_link"`
+	SourceType  string         `json:"source_type"`
+	CoverImg    string         `json:"cover_img"`
+	Tags        pq.StringArray `gorm:"type:text[]" json:"tags"`
+	Category    string         `json:"category"`
+	Author      string         `json:"author"`
+	Translator  string         `json:"translator"`
+}
+
+func (a *Article) content() ArticleContent {
+	return ArticleContent{
+		Title:       a.Title,
+		Description: a.Description,
+		Content:     a.Content,
+		SourceLink:  a.SourceLink,
+		SourceType:  a.SourceType,
+		CoverImg:    a.CoverImg,
+		Tags:        a.Tags,
+		Category:    a.Category,
+		Author:      a.Author,
+		Translator:  a.Translator,
+	}
+}
+
+// 写入博客表的字段
+func (c ArticleContent) columns() map[string]interface{} {
+	return map[string]interface{}{
+		"title":       c.Title,
+		"description": c.Description,
+		"content":     c.Content,
+		"source_link": c.SourceLink,
+		"source_type": c.SourceType,
+		"cover_img":   c.CoverImg,
+		"tags":        c.Tags,
+		"category":    c.Category,
+		"author":      c.Author,
+		"translator":  c.Translator,
+	}
+}
+
+// 博客的不可变修订记录，每次保存生成一条
+type ArticleRevision struct {
+	gorm.Model
+	ArticleId      uint `gorm:"uniqueIndex:idx_article_version;not null" json:"article_id"`
+	Version        uint `gorm:"uniqueIndex:idx_article_version;not null" json:"version"` // 从 1 递增
+	ArticleContent `gorm:"embedded"`
+	EditorId       uint   `json:"editor_id"`
+	Editor         *User  `gorm:"foreignKey:EditorId" json:"editor"`
+	Status         string `gorm:"index" json:"status"` // pending / applied / rejected / superseded
+	RestoredFrom   *uint  `json:"restored_from"`       // 由哪个版本恢复而来
+}
+
+// 创建新修订，版本号在博客行锁内递增
+func createRevision(tx *gorm.DB, articleId, editorId uint, content ArticleContent, status string) (*ArticleRevision, error) {
+	var version uint
+	err := tx.Model(&ArticleRevision{}).
+		Where("article_id = ?", articleId).
+		Select("COALESCE(MAX(version), 0) + 1").
+		Scan(&version).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rev := &ArticleRevision{
+		ArticleId:      articleId,
+		Version:        version,
+		ArticleContent: content,
+		EditorId:       editorId,
+		Status:         status,
+	}
+	return rev, tx.Create(rev).Error
+}
+
+func lockArticle(tx *gorm.DB, id uint) (*Article, error) {
+	var article Article
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&article, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrArticleNotFound
+	}
+	return &article, err
+}
+
+// 待审核的修订被新修改替代
+func supersedePendingRevision(tx *gorm.DB, article *Article) error {
+	if article.PendingRevisionId == nil {
+		return nil
+	}
+	return tx.Model(&ArticleRevision{}).
+		Where("id = ? AND status = ?", *article.PendingRevisionId, RevisionPending).
+		Update("status", RevisionSuperseded).Error
+}
+
+// SaveArticleRevision 保存一次修改：已发布的博客生成待审核修订，线上内容保持不变；
+// 其他状态直接写入博客，草稿保持草稿，其余重新进入待审核
+func SaveArticleRevision(ctx context.Context, articleId, editorId uint, content ArticleContent) (*Article, *ArticleRevision, error) {
+	return saveArticleRevision(ctx, articleId, editorId, content, nil)
+}
+
+func saveArticleRevision(ctx context.Context, articleId, editorId uint, content ArticleContent, restoredFrom *uint) (*Article, *ArticleRevision, error) {
+	var article *Article
+	var rev *ArticleRevision
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		article, err = lockArticle(tx, articleId)
+		if err != nil {
+			return err
+		}
+		if article.PublishStatus == PublishStatusArchived {
+			return ErrArticleArchived
+		}
+		if err := supersedePendingRevision(tx, article); err != nil {
+			return err
+		}
+
+		status := RevisionApplied
+		if article.PublishStatus == PublishStatusPublished {
+			status = RevisionPending
+		}
+		rev, err = createRevision(tx, articleId, editorId, content, status)
+		if err != nil {
+			return err
+		}
+		if restoredFrom != nil {
+			rev.RestoredFrom = restoredFrom
+			if err := tx.Model(rev).Update("restored_from", *restoredFrom).Error; err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{"updated_at": time.Now()}
+		if status == RevisionPending {
+			updates["pending_revision_id"] = rev.ID
+		} else {
+			updates = content.columns()
+			updates["revision_id"] = rev.ID
+			updates["pending_revision_id"] = nil
+			if article.PublishStatus != PublishStatusDraft {
+				updates["publish_status"] = PublishStatusSubmitted
+			}
+		}
+		from := article.PublishStatus
+		if err := tx.Model(article).Updates(updates).Error; err != nil {
+			return err
+		}
+		// 修改后重新进入待审核，记录在审核历史中，审核队列据此计算等待时间
+		if _, ok := updates["publish_status"]; ok && from != PublishStatusSubmitted {
+			err := tx.Create(&ContentReview{
+				TargetType: CommentTargetBlog,
+				TargetId:   articleId,
+				ReviewerId: editorId,
+				Action:     ReviewActionSubmit,
+				FromStatus: from,
+				ToStatus:   PublishStatusSubmitted,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.First(article, articleId).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return article, rev, nil
+}
+
+// RestoreArticleRevision 以历史版本的内容生成一次新的修改
+func RestoreArticleRevision(ctx context.Context, articleId, revisionId, editorId uint) (*Article, *ArticleRevision, error) {
+	old, err := GetArticleRevision(ctx, articleId, revisionId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return saveArticleRevision(ctx, articleId, editorId, old.ArticleContent, &old.ID)
+}
+
+// ReviewArticleRevision 审核已发布博客的待审核修订：通过则立即上线（路由要求 blog:publish），退回需填写意见
+func ReviewArticleRevision(ctx context.Context, articleId, revisionId, reviewerId uint, approve bool, note string) (*ArticleRevision, error) {
+	if !approve && note == "" {
+		return nil, ErrRevisionNoteMissing
+	}
+
+	var rev ArticleRevision
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		article, err := lockArticle(tx, articleId)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("id = ? AND article_id = ?", revisionId, articleId).First(&rev).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRevisionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if rev.Status != RevisionPending || article.PendingRevisionId == nil || *article.PendingRevisionId != rev.ID {
+			return ErrRevisionNotPending
+		}
+		if err := checkClaim(tx, ModerationTypeRevision, articleId, reviewerId); err != nil {
+			return err
+		}
+		// 修订离开待审核，认领随之释放
+		if err := tx.Where("target_type = ? AND target_id = ?", ModerationTypeRevision, articleId).Delete(&ModerationClaim{}).Error; err != nil {
+			return err
+		}
+
+		action, notificationType := ReviewActionRejectRevision, NotificationBlogChangesRequested
+		updates := map[string]interface{}{"pending_revision_id": nil, "updated_at": time.Now()}
+		rev.Status = RevisionRejected
+		if approve {
+			action, notificationType = ReviewActionApproveRevision, NotificationBlogApproved
+			for k, v := range rev.ArticleContent.columns() {
+				updates[k] = v
+			}
+			updates["revision_id"] = rev.ID
+			rev.Status = RevisionApplied
+		}
+
+		if err := tx.Model(&rev).Update("status", rev.Status).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(article).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		err = tx.Create(&ContentReview{
+			TargetType: CommentTargetBlog,
+			TargetId:   articleId,
+			ReviewerId: reviewerId,
+			Action:     action,
+			FromStatus: article.PublishStatus,
+			ToStatus:   article.PublishStatus,
+			Note:       note,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		content := article.Title
+		if note != "" {
+			content = article.Title + ": " + note
+		}
+		return createNotification(tx, &Notification{
+			UserId:     article.PublisherId,
+			ActorId:    reviewerId,
+			Type:       notificationType,
+			TargetType: CommentTargetBlog,
+			TargetId:   articleId,
+			Content:    content,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func GetArticleRevision(ctx context.Context, articleId, revisionId uint) (*ArticleRevision, error) {
+	var rev ArticleRevision
+	err := db.WithContext(ctx).Preload("Editor").
+		Where("id = ? AND article_id = ?", revisionId, articleId).
+		First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	return &rev, err
+}
+
+// GetPreviousArticleRevision 返回指定修订的上一个版本，没有时返回 nil
+func GetPreviousArticleRevision(ctx context.Context, rev *ArticleRevision) (*ArticleRevision, error) {
+	var prev ArticleRevision
+	err := db.WithContext(ctx).Where("article_id = ? AND version < ?", rev.ArticleId, rev.Version).
+		Order("version desc").
+		First(&prev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &prev, err
+}
+
+type ArticleRevisionFilter struct {
+	ArticleId uint
+	Page      int // 当前页码，从 1 开始
+	PageSize  int // 每页数量，建议默认 10
+}
+
+// QueryArticleRevisions 按版本倒序列出修订，列表不返回正文
+func QueryArticleRevisions(ctx context.Context, filter ArticleRevisionFilter) ([]ArticleRevision, int64, error) {
+	var revisions []ArticleRevision
+	var total int64
+
+	query := db.WithContext(ctx).Preload("Editor").Model(&ArticleRevision{}).Where("article_id = ?", filter.ArticleId)
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	query = query.Omit("content").Order("version desc")
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.Offset(offset).Limit(filter.PageSize)
+
+	err := query.Find(&revisions).Error
+	return revisions, total, err
+}
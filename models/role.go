@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
 
 type Permission struct {
 	gorm.Model
@@ -23,9 +27,9 @@ type Role struct {
 	PermissionGroups []PermissionGroup `gorm:"many2many:role_permission_groups;"`
 }
 
-func InitRolesAndPermissions() error {
+func InitRolesAndPermissions(ctx context.Context) error {
 	var count int64
-	if err := db.Model(&Permission{}).Count(&count).Error; err != nil {
+	if err := db.WithContext(ctx).Model(&Permission{}).Count(&count).Error; err != nil {
 		return err
 	}
 	if count > 0 {
@@ -47,7 +51,7 @@ func InitRolesAndPermissions() error {
 		{Name: "dapp:delete", Description: "删除Dapp"},
 		{Name: "dapp:publish", Description: "发布Dapp"},
 	}
-	if err := db.Create(&permissions).Error; err != nil {
+	if err := db.WithContext(ctx).Create(&permissions).Error; err != nil {
 		return err
 	}
 
@@ -62,7 +66,7 @@ func InitRolesAndPermissions() error {
 		{Name: "Dapp管理员", Description: "Dapp管理权限组"},
 		{Name: "超级管理员", Description: "拥有所有权限"},
 	}
-	if err := db.Create(&permissionGroups).Error; err != nil {
+	if err := db.WithContext(ctx).Create(&permissionGroups).Error; err != nil {
 		return err
 	}
 
@@ -70,14 +74,14 @@ func InitRolesAndPermissions() error {
 	// helper 函数：按权限名查权限ID
 	getPermByName := func(name string) (Permission, error) {
 		var p Permission
-		err := db.Where("name = ?", name).First(&p).Error
+		err := db.WithContext(ctx).Where("name = ?", name).First(&p).Error
 		return p, err
 	}
 
 	// 博客创作者：创作博客，删除博客
 	blogWrite, _ := getPermByName("blog:write")
 	blogDelete, _ := getPermByName("blog:delete")
-	err := db.Model(&permissionGroups[0]).Association("Permissions").Append(&blogWrite, &blogDelete)
+	err := db.WithContext(ctx).Model(&permissionGroups[0]).Association("Permissions").Append(&blogWrite, &blogDelete)
 	if err != nil {
 		return err
 	}
@@ -86,14 +90,14 @@ func InitRolesAndPermissions() error {
 	blogReview, _ := getPermByName("blog:review")
 	blogPublish, _ := getPermByName("blog:publish")
 	blogPermissions := []*Permission{&blogWrite, &blogReview, &blogDelete, &blogPublish}
-	err = db.Model(&permissionGroups[1]).Association("Permissions").Append(blogPermissions)
+	err = db.WithContext(ctx).Model(&permissionGroups[1]).Association("Permissions").Append(blogPermissions)
 	if err != nil {
 		return err
 	}
 
 	// 活动创建：新建活动
 	eventWrite, _ := getPermByName("event:write")
-	err = db.Model(&permissionGroups[2]).Association("Permissions").Append(&eventWrite)
+	err = db.WithContext(ctx).Model(&permissionGroups[2]).Association("Permissions").Append(&eventWrite)
 	if err != nil {
 		return err
 	}
@@ -103,21 +107,21 @@ func InitRolesAndPermissions() error {
 	eventDelete, _ := getPermByName("event:delete")
 	eventPublish, _ := getPermByName("event:publish")
 	eventPermissions := []*Permission{&eventWrite, &eventReview, &eventDelete, &eventPublish}
-	err = db.Model(&permissionGroups[3]).Association("Permissions").Append(eventPermissions)
+	err = db.WithContext(ctx).Model(&permissionGroups[3]).Association("Permissions").Append(eventPermissions)
 	if err != nil {
 		return err
 	}
 
 	// 内容创作者
 	contentPermissions := []*Permission{&blogWrite, &blogDelete}
-	err = db.Model(&permissionGroups[4]).Association("Permissions").Append(contentPermissions)
+	err = db.WithContext(ctx).Model(&permissionGroups[4]).Association("Permissions").Append(contentPermissions)
 	if err != nil {
 		return err
 
 	}
 
 	// 内容管理员：拥有所有内容管理权限
-	err = db.Model(&permissionGroups[5]).Association("Permissions").Append(blogPermissions)
+	err = db.WithContext(ctx).Model(&permissionGroups[5]).Association("Permissions").Append(blogPermissions)
 	if err != nil {
 		return err
 
@@ -128,14 +132,14 @@ func InitRolesAndPermissions() error {
 	dappDelete, _ := getPermByName("dapp:delete")
 	dappPublish, _ := getPermByName("dapp:publish")
 	dappPermissions := []*Permission{&dappWrite, &dappReview, &dappDelete, &dappPublish}
-	err = db.Model(&permissionGroups[6]).Association("Permissions").Append(dappPermissions)
+	err = db.WithContext(ctx).Model(&permissionGroups[6]).Association("Permissions").Append(dappPermissions)
 	if err != nil {
 		return err
 
 	}
 
 	// 超级管理员：拥有所有权限
-	err = db.Model(&permissionGroups[7]).Association("Permissions").Append(blogPermissions, eventPermissions, dappPermissions)
+	err = db.WithContext(ctx).Model(&permissionGroups[7]).Association("Permissions").Append(blogPermissions, eventPermissions, dappPermissions)
 	if err != nil {
 		return err
 	}
@@ -152,47 +156,47 @@ func InitRolesAndPermissions() error {
 		{Name: "super_admin", Description: "超级管理员角色"},
 	}
 
-	if err := db.Create(&roles).Error; err != nil {
+	if err := db.WithContext(ctx).Create(&roles).Error; err != nil {
 		return err
 	}
 
 	// 关联角色与权限组（角色继承权限组权限）
-	err = db.Model(&roles[0]).Association("PermissionGroups").Append(&permissionGroups[0]) // 博客作者
+	err = db.WithContext(ctx).Model(&roles[0]).Association("PermissionGroups").Append(&permissionGroups[0]) // 博客作者
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&roles[1]).Association("PermissionGroups").Append(&permissionGroups[1]) // 博客管理员
+	err = db.WithContext(ctx).Model(&roles[1]).Association("PermissionGroups").Append(&permissionGroups[1]) // 博客管理员
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&roles[2]).Association("PermissionGroups").Append(&permissionGroups[2]) // 活动创建者
+	err = db.WithContext(ctx).Model(&roles[2]).Association("PermissionGroups").Append(&permissionGroups[2]) // 活动创建者
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&roles[3]).Association("PermissionGroups").Append(&permissionGroups[3]) // 活动管理员
+	err = db.WithContext(ctx).Model(&roles[3]).Association("PermissionGroups").Append(&permissionGroups[3]) // 活动管理员
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&roles[4]).Association("PermissionGroups").Append(&permissionGroups[4]) // 内容创作者
+	err = db.WithContext(ctx).Model(&roles[4]).Association("PermissionGroups").Append(&permissionGroups[4]) // 内容创作者
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&roles[5]).Association("PermissionGroups").Append(&permissionGroups[5]) // 内容管理员
+	err = db.WithContext(ctx).Model(&roles[5]).Association("PermissionGroups").Append(&permissionGroups[5]) // 内容管理员
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&roles[6]).Association("PermissionGroups").Append(&permissionGroups[6]) // Dapp 管理员
+	err = db.WithContext(ctx).Model(&roles[6]).Association("PermissionGroups").Append(&permissionGroups[6]) // Dapp 管理员
 	if err != nil {
 		return err
 	}
 
-	err = db.Model(&roles[7]).Association("PermissionGroups").Append(&permissionGroups[7]) // 超级管理员
+	err = db.WithContext(ctx).Model(&roles[7]).Association("PermissionGroups").Append(&permissionGroups[7]) // 超级管理员
 	if err != nil {
 		return err
 	}
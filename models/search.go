@@ -0,0 +1,253 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"hyperlane/logger"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	SearchTypePost  = "post"
+	SearchTypeBlog  = "blog"
+	SearchTypeEvent = "event"
+)
+
+// 全文检索配置：search.config 为 Postgres text search configuration（安装 zhparser 后可用中文分词配置），
+// search.trigram 开启 pg_trgm 作为中文等无空格文本的子串匹配兜底
+var (
+	searchConfig  = "simple"
+	searchTrigram = false
+)
+
+var searchConfigPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// 参与检索的内容：search_vector 由 columns 依次按 A/B/C 权重生成，snippet 为高亮片段的来源，
+// trigram 为 pg_trgm 子串匹配的字段；已发布的博客/活动才参与检索
+var searchSources = []struct {
+	typ     string
+	table   string
+	columns []string
+	snippet string
+	trigram []string
+	where   string
+}{
+	{SearchTypePost, "posts", []string{"title", "description"}, "description",
+		[]string{"title", "description"}, ""},
+	{SearchTypeBlog, "articles", []string{"title", "description", "content"}, "coalesce(description, '') || ' ' || coalesce(content, '')",
+		[]string{"title", "description", "content"}, " AND publish_status = 2"},
+	{SearchTypeEvent, "events", []string{"title", "description", "location"}, "description",
+		[]string{"title", "description"}, " AND publish_status = 2"},
+}
+
+// InitSearch 读取检索配置；search_vector 列和索引由迁移以 simple 配置创建，
+// search.config 与之不同时在这里按新配置重建
+func InitSearch(ctx context.Context) {
+	if cfg := viper.GetString("search.config"); cfg != "" {
+		if !searchConfigPattern.MatchString(cfg) {
+			logger.Log.Warnf("invalid search.config %q, fallback to simple", cfg)
+		} else if err := syncSearchVectors(ctx, cfg); err != nil {
+			logger.Log.Errorf("apply search.config %q: %v, fallback to simple", cfg, err)
+		} else {
+			searchConfig = cfg
+		}
+	}
+
+	if !viper.GetBool("search.trigram") {
+		return
+	}
+	// pg_trgm 由迁移尝试安装，这里只检测是否可用
+	var exists bool
+	if err := db.WithContext(ctx).Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')").Scan(&exists).Error; err != nil {
+		logger.Log.Errorf("detect pg_trgm: %v", err)
+		return
+	}
+	if !exists {
+		logger.Log.Warn("pg_trgm not installed, trigram search disabled")
+		return
+	}
+	searchTrigram = true
+}
+
+func searchVectorExpr(cfg string, columns []string) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		parts[i] = fmt.Sprintf("setweight(to_tsvector('%s', coalesce(%s, '')), '%c')", cfg, col, 'A'+i)
+	}
+	return strings.Join(parts, " || ")
+}
+
+// syncSearchVectors 检查各表 search_vector 生成列使用的配置，与 cfg 不一致时重建该列和索引。
+// 重建会改写整张表，多实例同时启动时由咨询锁保证只执行一次
+func syncSearchVectors(ctx context.Context, cfg string) error {
+	var exists bool
+	if err := db.WithContext(ctx).Raw("SELECT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = ?)", cfg).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("text search configuration %q not found", cfg)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('search_vector'))").Error; err != nil {
+			return err
+		}
+		for _, s := range searchSources {
+			// 迁移未执行时没有该列，跳过
+			var expr string
+			err := tx.Raw(`SELECT coalesce(generation_expression, '') FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = ? AND column_name = 'search_vector'`, s.table).
+				Scan(&expr).Error
+			if err != nil {
+				return err
+			}
+			if expr == "" || strings.Contains(expr, "'"+cfg+"'::regconfig") {
+				continue
+			}
+
+			logger.Log.Warnf("rebuilding %s.search_vector with text search configuration %s", s.table, cfg)
+			stmts := []string{
+				fmt.Sprintf("ALTER TABLE %s DROP COLUMN search_vector", s.table),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (%s) STORED", s.table, searchVectorExpr(cfg, s.columns)),
+				fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_search ON %s USING GIN (search_vector)", s.table, s.table),
+			}
+			for _, stmt := range stmts {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+type SearchFilter struct {
+	Keyword  string
+	Type     string // post / blog / event，空表示全部
+	Page     int    // 当前页码，从 1 开始
+	PageSize int    // 每页数量，建议默认 10
+}
+
+type SearchHit struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"` // 高亮片段（HTML），原文已转义，命中词以 <mark></mark> 包裹
+	Rank      float64   `json:"rank"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchResult struct {
+	Hits   []SearchHit      `json:"hits"`
+	Facets map[string]int64 `json:"facets"` // 各类型命中数
+	Total  int64            `json:"total"`
+}
+
+// 构造各类型的命中子查询，只计算排序需要的字段，高亮片段在分页后由 searchSnippetSQL 生成
+func searchHitsSQL() string {
+	var parts []string
+	for _, s := range searchSources {
+		rank := "ts_rank(search_vector, q)"
+		match := "search_vector @@ q"
+		if searchTrigram {
+			rank += " + similarity(title, @kw)"
+			for _, col := range s.trigram {
+				match += " OR " + col + " ILIKE @like"
+			}
+			match = "(" + match + ")"
+		}
+
+		parts = append(parts, fmt.Sprintf(`
+			SELECT '%s' AS type, id, title, %s AS rank, created_at
+			FROM %s, websearch_to_tsquery('%s', @kw) q
+			WHERE deleted_at IS NULL AND %s%s`,
+			s.typ, rank, s.table, searchConfig, match, s.where))
+	}
+	return strings.Join(parts, " UNION ALL ")
+}
+
+// 先做 HTML 转义再交给 ts_headline，片段中只有 <mark> 是标签，原文里的标签不会被当作 HTML 输出
+func htmlEscapeSQL(expr string) string {
+	return "replace(replace(replace(replace(replace(" + expr +
+		`, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// 按 hits.type 回表生成当前页每条结果的高亮片段
+func searchSnippetSQL() string {
+	var b strings.Builder
+	b.WriteString("CASE hits.type")
+	for _, s := range searchSources {
+		fmt.Fprintf(&b, `
+			WHEN '%s' THEN (SELECT ts_headline('%s', %s, q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')
+				FROM %s WHERE %s.id = hits.id)`,
+			s.typ, searchConfig, htmlEscapeSQL("coalesce("+s.snippet+", '')"), s.table, s.table)
+	}
+	b.WriteString(" END")
+	return b.String()
+}
+
+// ILIKE 默认以反斜杠转义，关键词中的 % 和 _ 按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Search 跨帖子、博客、活动全文检索，按相关度排序并返回类型分面
+func Search(ctx context.Context, filter SearchFilter) (*SearchResult, error) {
+	result := SearchResult{
+		Hits:   []SearchHit{},
+		Facets: map[string]int64{SearchTypePost: 0, SearchTypeBlog: 0, SearchTypeEvent: 0},
+	}
+
+	hitsSQL := searchHitsSQL()
+	named := map[string]interface{}{
+		"kw":   filter.Keyword,
+		"like": "%" + likeEscaper.Replace(filter.Keyword) + "%",
+	}
+
+	// 分面统计
+	var facets []struct {
+		Type  string
+		Count int64
+	}
+	err := db.WithContext(ctx).Raw("SELECT type, COUNT(*) AS count FROM ("+hitsSQL+") hits GROUP BY type", named).
+		Scan(&facets).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range facets {
+		result.Facets[f.Type] = f.Count
+		if filter.Type == "" || filter.Type == f.Type {
+			result.Total += f.Count
+		}
+	}
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	named["limit"] = filter.PageSize
+	named["offset"] = (filter.Page - 1) * filter.PageSize
+
+	page := "SELECT * FROM (" + hitsSQL + ") hits"
+	if filter.Type != "" {
+		page += " WHERE type = @type"
+		named["type"] = filter.Type
+	}
+	page += " ORDER BY rank DESC, created_at DESC LIMIT @limit OFFSET @offset"
+
+	// 只为当前页生成高亮片段
+	query := "SELECT hits.*, " + searchSnippetSQL() + " AS snippet" +
+		" FROM (" + page + ") hits, websearch_to_tsquery('" + searchConfig + "', @kw) q" +
+		" ORDER BY hits.rank DESC, hits.created_at DESC"
+
+	if err := db.WithContext(ctx).Raw(query, named).Scan(&result.Hits).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
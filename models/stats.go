@@ -1,7 +1,8 @@
 package models
 
 import (
-	"log"
+	"context"
+	"hyperlane/logger"
 	"time"
 
 	"gorm.io/gorm"
@@ -37,7 +38,7 @@ type StatsResponse struct {
 }
 
 // 提供给 Controller 调用的接口
-func GetStatsOverview() (StatsResponse, error) {
+func GetStatsOverview(ctx context.Context) (StatsResponse, error) {
 	var resp StatsResponse
 
 	now := time.Now()
@@ -46,7 +47,7 @@ func GetStatsOverview() (StatsResponse, error) {
 	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 
 	var stats []DailyStats
-	if err := db.Where("date >= ?", startOfMonth).Order("date asc").Find(&stats).Error; err != nil {
+	if err := db.WithContext(ctx).Where("date >= ?", startOfMonth).Order("date asc").Find(&stats).Error; err != nil {
 		return resp, err
 	}
 	if len(stats) == 0 {
@@ -65,7 +66,7 @@ func GetStatsOverview() (StatsResponse, error) {
 	}
 	resp.Overview = &statsOverview
 
-	trend, err := GetLast7DaysStats()
+	trend, err := GetLast7DaysStats(ctx)
 	if err != nil {
 		return resp, err
 	}
@@ -109,14 +110,14 @@ func calcGrowth(base, delta int) float64 {
 	return (float64(delta) / float64(base)) * 100.0
 }
 
-func CollectDailyStats() error {
+func CollectDailyStats(ctx context.Context) error {
 	// 获取当天日期（去掉时分秒）
 	today := time.Now().Truncate(24 * time.Hour)
 
 	var existing DailyStats
-	err := db.Where("date = ?", today).First(&existing).Error
+	err := db.WithContext(ctx).Where("date = ?", today).First(&existing).Error
 	if err == nil {
-		log.Println("Stats already collected for today")
+		logger.Log.Info("Stats already collected for today")
 		return nil
 	}
 
@@ -125,10 +126,10 @@ func CollectDailyStats() error {
 	var eventsCount int64
 	var postsCount int64
 
-	db.Model(&User{}).Count(&usersCount)
-	db.Model(&Article{}).Count(&blogsCount)
-	db.Model(&Event{}).Count(&eventsCount)
-	db.Model(&Post{}).Count(&postsCount)
+	db.WithContext(ctx).Model(&User{}).Count(&usersCount)
+	db.WithContext(ctx).Model(&Article{}).Count(&blogsCount)
+	db.WithContext(ctx).Model(&Event{}).Count(&eventsCount)
+	db.WithContext(ctx).Model(&Post{}).Count(&postsCount)
 
 	stats := DailyStats{
 		Date:   today,
@@ -137,12 +138,12 @@ func CollectDailyStats() error {
 		Events: int(eventsCount),
 		Posts:  int(postsCount),
 	}
-	if err := db.Create(&stats).Error; err != nil {
-		log.Println("Failed to store daily stats:", err)
+	if err := db.WithContext(ctx).Create(&stats).Error; err != nil {
+		logger.Log.Errorf("Failed to store daily stats: %v", err)
 		return err
 	}
 
-	log.Println("Daily stats collected for", today.Format("2006-01-02"))
+	logger.Log.Infof("Daily stats collected for %s", today.Format("2006-01-02"))
 	return nil
 }
 
@@ -154,11 +155,11 @@ type TimeSeriesData struct {
 	Posts  int    `json:"posts"`
 }
 
-func GetLast7DaysStats() ([]TimeSeriesData, error) {
+func GetLast7DaysStats(ctx context.Context) ([]TimeSeriesData, error) {
 	var statsList []DailyStats
 	sevenDaysAgo := time.Now().AddDate(0, 0, -6).Truncate(24 * time.Hour) // 含当天共7天
 
-	err := db.Where("date >= ?", sevenDaysAgo).
+	err := db.WithContext(ctx).Where("date >= ?", sevenDaysAgo).
 		Order("date ASC").
 		Find(&statsList).Error
 	if err != nil {
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrLoginCodeInvalid    = errors.New("invalid login code")
+)
+
+// 刷新令牌只保存哈希；每次刷新都会轮换，同一次登录派生的令牌属于同一个 family
+type RefreshToken struct {
+	gorm.Model
+	UserId    uint       `gorm:"index;not null" json:"user_id"`
+	FamilyId  string     `gorm:"index;not null" json:"family_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`    // 已轮换
+	RevokedAt *time.Time `json:"revoked_at"` // 已吊销
+	UserAgent string     `json:"user_agent"`
+	IP        string     `json:"ip"`
+}
+
+// 主动吊销的 access token（按 jti），过期后可清理
+type RevokedToken struct {
+	gorm.Model
+	Jti       string    `gorm:"uniqueIndex;not null"`
+	UserId    uint      `gorm:"index"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// OAuth 回调签发的一次性登录码，前端用它 POST 换取令牌，避免令牌出现在 URL 中；只保存哈希
+type LoginCode struct {
+	gorm.Model
+	CodeHash  string    `gorm:"uniqueIndex;not null"`
+	UserId    uint      `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"index"`
+	UsedAt    *time.Time
+}
+
+func CreateLoginCode(ctx context.Context, lc *LoginCode) error {
+	return db.WithContext(ctx).Create(lc).Error
+}
+
+// ConsumeLoginCode 使用登录码并返回对应的用户 ID；登录码只能使用一次
+func ConsumeLoginCode(ctx context.Context, codeHash string) (uint, error) {
+	var userId uint
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var lc LoginCode
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code_hash = ?", codeHash).
+			First(&lc).Error
+		if err != nil {
+			return ErrLoginCodeInvalid
+		}
+
+		now := time.Now()
+		if lc.UsedAt != nil || now.After(lc.ExpiresAt) {
+			return ErrLoginCodeInvalid
+		}
+		if err := tx.Model(&lc).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		userId = lc.UserId
+		return nil
+	})
+	return userId, err
+}
+
+func CreateRefreshToken(ctx context.Context, rt *RefreshToken) error {
+	return db.WithContext(ctx).Create(rt).Error
+}
+
+// RotateRefreshToken 用旧令牌换新令牌；旧令牌已被使用过视为泄露，吊销整个 family
+func RotateRefreshToken(ctx context.Context, oldHash, newHash string, ttl time.Duration, userAgent, ip string) (*RefreshToken, error) {
+	var next RefreshToken
+	var reused bool
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", oldHash).
+			First(&current).Error
+		if err != nil {
+			return ErrRefreshTokenInvalid
+		}
+
+		now := time.Now()
+		if current.RevokedAt != nil || now.After(current.ExpiresAt) {
+			return ErrRefreshTokenInvalid
+		}
+		if current.UsedAt != nil {
+			reused = true
+			return revokeRefreshTokenFamily(tx, current.FamilyId)
+		}
+
+		if err := tx.Model(&current).Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		next = RefreshToken{
+			UserId:    current.UserId,
+			FamilyId:  current.FamilyId,
+			TokenHash: newHash,
+			ExpiresAt: now.Add(ttl),
+			UserAgent: userAgent,
+			IP:        ip,
+		}
+		return tx.Create(&next).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+	return &next, nil
+}
+
+func revokeRefreshTokenFamily(tx *gorm.DB, familyId string) error {
+	return tx.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", time.Now()).Error
+}
+
+// 退出登录：吊销该刷新令牌所在的 family
+func RevokeRefreshToken(ctx context.Context, userId uint, tokenHash string) error {
+	var rt RefreshToken
+	if err := db.WithContext(ctx).Where("token_hash = ? AND user_id = ?", tokenHash, userId).First(&rt).Error; err != nil {
+		return ErrRefreshTokenInvalid
+	}
+	return revokeRefreshTokenFamily(db.WithContext(ctx), rt.FamilyId)
+}
+
+func RevokeAccessToken(ctx context.Context, jti string, userId uint, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&RevokedToken{
+		Jti:       jti,
+		UserId:    userId,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+	var count int64
+	err := db.WithContext(ctx).Model(&RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// 退出所有设备：提升 token_version 使所有 access token 失效，并吊销全部刷新令牌
+func LogoutEverywhere(ctx context.Context, userId uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).
+			Where("id = ?", userId).
+			UpdateColumn("token_version", gorm.Expr("token_version + ?", 1)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userId).
+			Update("revoked_at", time.Now()).Error
+	})
+}
+
+// 清理过期的刷新令牌、登录码和吊销记录
+func DeleteExpiredTokens(ctx context.Context, now time.Time) error {
+	if err := db.WithContext(ctx).Unscoped().Where("expires_at < ?", now).Delete(&RefreshToken{}).Error; err != nil {
+		return err
+	}
+	if err := db.WithContext(ctx).Unscoped().Where("expires_at < ?", now).Delete(&LoginCode{}).Error; err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Unscoped().Where("expires_at < ?", now).Delete(&RevokedToken{}).Error
+}
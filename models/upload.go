@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"hyperlane/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUploadNotFound = utils.NewAppError(http.StatusNotFound, "UPLOAD_NOT_FOUND", "upload not found")
+	ErrUploadInUse    = utils.NewAppError(http.StatusConflict, "UPLOAD_IN_USE", "upload is still in use")
+)
+
+// 上传的图片，同一用户同一内容只记录一条；对象按内容哈希存储，不同用户上传相同内容共用同一对象
+type Upload struct {
+	gorm.Model
+	UserId   uint   `gorm:"uniqueIndex:idx_upload_owner_hash;not null" json:"user_id"`    // 上传者
+	Hash     string `gorm:"uniqueIndex:idx_upload_owner_hash;index;not null" json:"hash"` // 内容 SHA-256
+	Key      string `gorm:"not null" json:"key"`
+	ThumbKey string `json:"thumb_key"`
+	URL      string `gorm:"not null" json:"url"`
+	ThumbURL string `json:"thumb_url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// 引用上传文件的字段，未被任何字段引用的上传视为孤儿文件。
+// 头像、封面、Logo 保存完整 URL；正文和描述中嵌入的图片按 URL 子串匹配。
+// 博客的所有修订（包括待审核的）都可能被应用或恢复，其中的图片同样视为被引用
+var uploadReferences = []struct {
+	table    string
+	column   string
+	embedded bool
+}{
+	{table: "users", column: "avatar"},
+	{table: "events", column: "cover_img"},
+	{table: "events", column: "description", embedded: true},
+	{table: "articles", column: "cover_img"},
+	{table: "articles", column: "description", embedded: true},
+	{table: "articles", column: "content", embedded: true},
+	{table: "article_revisions", column: "cover_img"},
+	{table: "article_revisions", column: "description", embedded: true},
+	{table: "article_revisions", column: "content", embedded: true},
+	{table: "posts", column: "description", embedded: true},
+	{table: "recaps", column: "content", embedded: true},
+	{table: "comments", column: "content", embedded: true},
+	{table: "dapps", column: "logo"},
+	{table: "dapps", column: "description", embedded: true},
+}
+
+var uploadReferencedSQL = buildUploadReferencedSQL()
+
+// 没有缩略图时 thumb_url 为空字符串，不能参与匹配，否则会命中所有空字段
+func buildUploadReferencedSQL() string {
+	conds := make([]string, len(uploadReferences))
+	for i, ref := range uploadReferences {
+		col := ref.table + "." + ref.column
+		match := col + " IN (uploads.url, NULLIF(uploads.thumb_url, ''))"
+		if ref.embedded {
+			match = "(strpos(" + col + ", uploads.url) > 0 OR (uploads.thumb_url <> '' AND strpos(" + col + ", uploads.thumb_url) > 0))"
+		}
+		conds[i] = "EXISTS (SELECT 1 FROM " + ref.table + " WHERE " + match + " AND " + ref.table + ".deleted_at IS NULL)"
+	}
+	return strings.Join(conds, "\n\tOR ")
+}
+
+// CreateUpload 记录上传，用户重复上传相同内容时返回已有记录
+func CreateUpload(ctx context.Context, u *Upload) (*Upload, error) {
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "hash"}},
+		DoNothing: true,
+	}).Create(u).Error
+	if err != nil {
+		return nil, err
+	}
+	if u.ID != 0 {
+		return u, nil
+	}
+	return GetUserUploadByHash(ctx, u.UserId, u.Hash)
+}
+
+func GetUserUploadByHash(ctx context.Context, userID uint, hash string) (*Upload, error) {
+	var u Upload
+	err := db.WithContext(ctx).Where("user_id = ? AND hash = ?", userID, hash).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &u, err
+}
+
+// GetUploadByHash 查找任意用户上传的相同内容，用于复用已存储的对象
+func GetUploadByHash(ctx context.Context, hash string) (*Upload, error) {
+	var u Upload
+	err := db.WithContext(ctx).Where("hash = ?", hash).Order("id").First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &u, err
+}
+
+type UploadFilter struct {
+	UserId   uint
+	Page     int // 当前页码，从 1 开始
+	PageSize int // 每页数量，建议默认 10
+}
+
+func QueryUploads(ctx context.Context, filter UploadFilter) ([]Upload, int64, error) {
+	var uploads []Upload
+	var total int64
+
+	query := db.WithContext(ctx).Model(&Upload{}).Where("user_id = ?", filter.UserId)
+
+	// 统计总数（不加 limit 和 offset）
+	query.Count(&total)
+
+	query = query.Order("created_at desc")
+
+	// 分页
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 10
+	}
+	offset := (filter.Page - 1) * filter.PageSize
+	query = query.Offset(offset).Limit(filter.PageSize)
+
+	err := query.Find(&uploads).Error
+	return uploads, total, err
+}
+
+// DeleteUserUpload 删除用户自己未被引用的上传，返回被删除的记录及对象是否已无人使用
+func DeleteUserUpload(ctx context.Context, userID, id uint) (*Upload, bool, error) {
+	var u Upload
+	err := db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var inUse bool
+	if err := db.WithContext(ctx).Raw("SELECT "+uploadReferencedSQL+" FROM uploads WHERE id = ?", u.ID).Scan(&inUse).Error; err != nil {
+		return nil, false, err
+	}
+	if inUse {
+		return nil, false, ErrUploadInUse
+	}
+
+	unused, err := deleteUploadRecord(ctx, &u)
+	return &u, unused, err
+}
+
+// QueryOrphanUploads 查询 before 之前上传且未被引用的文件
+func QueryOrphanUploads(ctx context.Context, before time.Time, limit int) ([]Upload, error) {
+	var uploads []Upload
+	err := db.WithContext(ctx).Where("created_at < ?", before).
+		Where("NOT (" + uploadReferencedSQL + ")").
+		Order("id").
+		Limit(limit).
+		Find(&uploads).Error
+	return uploads, err
+}
+
+// DeleteOrphanUpload 删除孤儿文件记录，返回对象是否已无人使用（可以从存储中删除）
+func DeleteOrphanUpload(ctx context.Context, u *Upload) (bool, error) {
+	return deleteUploadRecord(ctx, u)
+}
+
+// 物理删除记录并检查是否还有其他记录共用同一对象
+func deleteUploadRecord(ctx context.Context, u *Upload) (bool, error) {
+	unused := false
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&Upload{}, u.ID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Unscoped().Model(&Upload{}).Where("hash = ?", u.Hash).Count(&count).Error; err != nil {
+			return err
+		}
+		unused = count == 0
+		return nil
+	})
+	return unused, err
+}
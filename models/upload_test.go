@@ -0,0 +1,124 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"hyperlane/migrations"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// 需要 PostgreSQL：设置 TEST_DATABASE_DSN 指向测试库后运行。
+// 迁移会在该库上执行，测试数据写在事务中，结束时回滚
+func useTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+
+	gdb, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(context.Background(), sqlDB); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	tx := gdb.Begin()
+	prev := db
+	SetDB(tx)
+	t.Cleanup(func() {
+		tx.Rollback()
+		SetDB(prev)
+	})
+	return tx
+}
+
+func TestQueryOrphanUploads(t *testing.T) {
+	tx := useTestDB(t)
+
+	run := time.Now().UnixNano()
+	url := func(name string) string {
+		return fmt.Sprintf("https://cdn.example.com/%d/%s.png", run, name)
+	}
+	mustCreate := func(v any) {
+		t.Helper()
+		if err := tx.Create(v).Error; err != nil {
+			t.Fatalf("create %T: %v", v, err)
+		}
+	}
+
+	user := User{Email: fmt.Sprintf("upload-gc-%d@example.com", run)}
+	mustCreate(&user)
+
+	article := Article{
+		Title:       "gc",
+		Content:     fmt.Sprintf(`<p>正文</p><img src="%s">`, url("article-content")),
+		PublisherId: user.ID,
+	}
+	mustCreate(&article)
+	mustCreate(&ArticleRevision{
+		ArticleId: article.ID,
+		Version:   1,
+		ArticleContent: ArticleContent{
+			Title:       "gc",
+			Description: fmt.Sprintf(`<img src="%s">`, url("revision-description")),
+			CoverImg:    url("pending-revision-cover"),
+		},
+		EditorId: user.ID,
+		Status:   RevisionPending,
+	})
+	mustCreate(&Post{Description: "![](" + url("post-thumb") + ")", UserId: user.ID})
+	mustCreate(&Dapp{Name: "gc", Logo: url("dapp-logo"), PublisherId: user.ID})
+
+	tests := []struct {
+		name       string
+		upload     Upload
+		wantOrphan bool
+	}{
+		{name: "Embedded in article content", upload: Upload{URL: url("article-content")}},
+		{name: "Pending revision cover only", upload: Upload{URL: url("pending-revision-cover")}},
+		{name: "Embedded in revision description only", upload: Upload{URL: url("revision-description")}},
+		{name: "Thumbnail embedded in post", upload: Upload{URL: url("post-original"), ThumbURL: url("post-thumb")}},
+		{name: "Dapp logo", upload: Upload{URL: url("dapp-logo")}},
+		{name: "Unreferenced", upload: Upload{URL: url("unused")}, wantOrphan: true},
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for i := range tests {
+		u := &tests[i].upload
+		u.UserId = user.ID
+		u.Hash = fmt.Sprintf("%d-%d", run, i)
+		u.Key = u.Hash
+		u.CreatedAt = old
+		mustCreate(u)
+	}
+
+	orphans, err := QueryOrphanUploads(context.Background(), time.Now().Add(-24*time.Hour), 1000)
+	if err != nil {
+		t.Fatalf("QueryOrphanUploads: %v", err)
+	}
+	orphan := make(map[uint]bool, len(orphans))
+	for _, u := range orphans {
+		orphan[u.ID] = true
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orphan[tt.upload.ID]; got != tt.wantOrphan {
+				t.Errorf("orphan = %v, want %v", got, tt.wantOrphan)
+			}
+		})
+	}
+}
@@ -1,77 +1,85 @@
 package models
 
 import (
+	"context"
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
-	Email    string    `gorm:"unique;not null" json:"email"`
-	Username string    `json:"username"`
-	Avatar   string    `json:"avatar"`
-	Github   string    `json:"github"`
-	Twitter  string    `json:"twitter"`
-	Uid      uint      `json:"-"` // OAUTH
-	RoleID   uint      `json:"-"`
-	Role     *Role     `gorm:"foreignKey:RoleID" json:"-"`
-	Events   []Event   `gorm:"foreignKey:UserId" json:"events"`
-	Articles []Article `gorm:"foreignKey:PublisherId"  json:"articles"`
-	Posts    []Post    `gorm:"foreignKey:UserId" json:"posts"`
+	Email        string    `gorm:"unique;not null" json:"email"`
+	Username     string    `json:"username"`
+	Avatar       string    `json:"avatar"`
+	Github       string    `json:"github"`
+	Twitter      string    `json:"twitter"`
+	Uid          uint      `json:"-"` // OAUTH
+	RoleID       uint      `json:"-"`
+	Role         *Role     `gorm:"foreignKey:RoleID" json:"-"`
+	TokenVersion uint      `gorm:"default:0" json:"-"` // 递增后该用户已签发的 access token 全部失效
+	Events       []Event   `gorm:"foreignKey:UserId" json:"events"`
+	Articles     []Article `gorm:"foreignKey:PublisherId"  json:"articles"`
+	Posts        []Post    `gorm:"foreignKey:UserId" json:"posts"`
 }
 
-func GetUserByUid(uid uint) (*User, error) {
+func GetUserByUid(ctx context.Context, uid uint) (*User, error) {
 	var u User
-	if err := db.Where("uid = ?", uid).First(&u).Error; err != nil {
+	if err := db.WithContext(ctx).Where("uid = ?", uid).First(&u).Error; err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
-func GetUserById(id uint) (*User, error) {
+func GetUserById(ctx context.Context, id uint) (*User, error) {
 	var u User
-	if err := db.Where("id = ?", id).First(&u).Error; err != nil {
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&u).Error; err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
-func CreateUser(u *User) error {
+func CreateUser(ctx context.Context, u *User) error {
 	var role Role
-	if err := db.Where("name = ?", "content_creator").First(&role).Error; err != nil {
+	if err := db.WithContext(ctx).Where("name = ?", "content_creator").First(&role).Error; err != nil {
 		return err
 	}
 
 	// 设置默认角色 ID
 	u.RoleID = role.ID
 
-	if err := db.Create(u).Error; err != nil {
+	if err := db.WithContext(ctx).Create(u).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func UpdateUser(u *User) error {
-	if err := db.Save(u).Error; err != nil {
+func UpdateUser(ctx context.Context, u *User) error {
+	if err := db.WithContext(ctx).Save(u).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func GetUserByEmail(u *User) error {
-	if err := db.Where("email = ?", u.Email).First(u).Error; err != nil {
+func GetUserByEmail(ctx context.Context, u *User) error {
+	if err := db.WithContext(ctx).Where("email = ?", u.Email).First(u).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func GetUserWithPermissions(uid uint) ([]string, error) {
+func GetUserWithPermissions(ctx context.Context, uid uint) ([]string, error) {
+	perms, _, err := GetUserAuthState(ctx, uid)
+	return perms, err
+}
+
+// 返回用户当前的权限列表和 token 版本，供 JWT 校验使用
+func GetUserAuthState(ctx context.Context, uid uint) ([]string, uint, error) {
 	var user User
-	err := db.Preload("Role").
+	err := db.WithContext(ctx).Preload("Role").
 		Preload("Role.Permissions").
 		Preload("Role.PermissionGroups.Permissions").
 		First(&user, uid).Error
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	permSet := map[string]struct{}{}
@@ -92,7 +100,7 @@ func GetUserWithPermissions(uid uint) ([]string, error) {
 	for name := range permSet {
 		perms = append(perms, name)
 	}
-	return perms, nil
+	return perms, user.TokenVersion, nil
 }
 
 type Follow struct {
@@ -105,24 +113,36 @@ type Follow struct {
 }
 
 // 关注
-func FollowUser(followerID, followingID uint) error {
+func FollowUser(ctx context.Context, followerID, followingID uint) error {
 	follow := Follow{
 		FollowerID:  followerID,
 		FollowingID: followingID,
 	}
-	return db.Create(&follow).Error
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&follow).Error; err != nil {
+			return err
+		}
+
+		return createNotification(tx, &Notification{
+			UserId:     followingID,
+			ActorId:    followerID,
+			Type:       NotificationFollow,
+			TargetType: "user",
+			TargetId:   followerID,
+		})
+	})
 }
 
 // 取消关注
-func UnfollowUser(followerID, followingID uint) error {
-	return db.Where("follower_id = ? AND following_id = ?", followerID, followingID).
+func UnfollowUser(ctx context.Context, followerID, followingID uint) error {
+	return db.WithContext(ctx).Where("follower_id = ? AND following_id = ?", followerID, followingID).
 		Delete(&Follow{}).Error
 }
 
 // 检查是否已关注
-func IsFollowing(followerID, followingID uint) (bool, error) {
+func IsFollowing(ctx context.Context, followerID, followingID uint) (bool, error) {
 	var follow Follow
-	err := db.Where("follower_id = ? AND following_id = ?", followerID, followingID).First(&follow).Error
+	err := db.WithContext(ctx).Where("follower_id = ? AND following_id = ?", followerID, followingID).First(&follow).Error
 	if err == gorm.ErrRecordNotFound {
 		return false, nil
 	}
@@ -138,7 +158,7 @@ type FollowState struct {
 }
 
 // 批量检查是否关注
-func GetFollowingStates(followerID uint, userIDs []uint) ([]FollowState, error) {
+func GetFollowingStates(ctx context.Context, followerID uint, userIDs []uint) ([]FollowState, error) {
 	// 默认全部 false
 	states := make([]FollowState, 0, len(userIDs))
 	stateMap := make(map[uint]bool, len(userIDs))
@@ -148,7 +168,7 @@ func GetFollowingStates(followerID uint, userIDs []uint) ([]FollowState, error)
 
 	// 查找已关注的
 	var follows []Follow
-	if err := db.Where("follower_id = ? AND following_id IN ?", followerID, userIDs).
+	if err := db.WithContext(ctx).Where("follower_id = ? AND following_id IN ?", followerID, userIDs).
 		Find(&follows).Error; err != nil {
 		return nil, err
 	}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 可统计浏览量的内容及对应的表
+var viewTables = map[string]string{
+	CommentTargetPost:  "posts",
+	CommentTargetBlog:  "articles",
+	CommentTargetEvent: "events",
+}
+
+// ViewTargetValid 判断内容类型是否支持浏览量统计
+func ViewTargetValid(targetType string) bool {
+	_, ok := viewTables[targetType]
+	return ok
+}
+
+// IncrementViewCounts 把一批浏览量增量合并为一条 UPDATE 写入，counts 为内容 ID -> 增量
+func IncrementViewCounts(ctx context.Context, targetType string, counts map[uint]uint) error {
+	table, ok := viewTables[targetType]
+	if !ok {
+		return fmt.Errorf("unknown view target: %s", targetType)
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	// 按 ID 排序，多个实例同时写入时加锁顺序一致，避免死锁
+	ids := make([]uint, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := make([]string, len(ids))
+	args := make([]interface{}, 0, 2*len(ids))
+	for i, id := range ids {
+		rows[i] = "(CAST(? AS bigint), CAST(? AS bigint))"
+		args = append(args, id, counts[id])
+	}
+	sql := "UPDATE " + table + " AS t SET view_count = COALESCE(t.view_count, 0) + v.n" +
+		" FROM (VALUES " + strings.Join(rows, ", ") + ") AS v(id, n) WHERE t.id = v.id"
+	return db.WithContext(ctx).Exec(sql, args...).Error
+}
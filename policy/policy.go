@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"slices"
+
+	"hyperlane/models"
+	"hyperlane/utils"
+)
+
+// 受控资源，内容类取值与 models.CommentTarget* 一致
+const (
+	Post    = "post"
+	Comment = "comment"
+	Blog    = "blog"
+	Event   = "event"
+	Recap   = "recap"
+	Dapp    = "dapp"
+	User    = "user"
+)
+
+// 对单个资源的操作
+const (
+	Update        = "update"
+	Delete        = "delete"
+	History       = "history"       // 查看审核历史和修订
+	Registrations = "registrations" // 管理活动报名
+)
+
+// Scope 用户对某类资源某个操作的范围
+type Scope string
+
+const (
+	ScopeNone Scope = "none"
+	ScopeOwn  Scope = "own" // 仅自己的资源
+	ScopeAny  Scope = "any" // 任何人的资源
+)
+
+// Subject 发起操作的用户及其权限
+type Subject struct {
+	UserId      uint
+	Permissions []string
+}
+
+func (s Subject) has(perm string) bool {
+	return slices.Contains(s.Permissions, perm)
+}
+
+// Rule 所有者持有 Owner 中全部权限时可以操作自己的资源，
+// 持有 Any 中任一权限时可以操作任何人的资源
+type Rule struct {
+	Owner []string
+	Any   []string
+}
+
+// Scope 返回 Subject 在该规则下的操作范围
+func (r Rule) Scope(s Subject) Scope {
+	if slices.ContainsFunc(r.Any, s.has) {
+		return ScopeAny
+	}
+	for _, perm := range r.Owner {
+		if !s.has(perm) {
+			return ScopeNone
+		}
+	}
+	return ScopeOwn
+}
+
+// 审核人员（*:review）负责处理他人的内容；各角色默认权限见 models.InitRolesAndPermissions，
+// 其中博客作者也持有 blog:delete，因此不能用它判断能否删除他人的博客
+var rules = map[string]map[string]Rule{
+	Post: {
+		Update: {Owner: []string{"blog:write"}},
+		Delete: {Owner: []string{"blog:delete"}, Any: []string{"blog:review"}},
+	},
+	// 评论作者本人可以编辑和删除，审核人员可以删除任何评论
+	Comment: {
+		Update: {},
+		Delete: {Any: []string{"blog:review", "event:review"}},
+	},
+	Blog: {
+		Update:  {Owner: []string{"blog:write"}},
+		Delete:  {Owner: []string{"blog:delete"}, Any: []string{"blog:review"}},
+		History: {Any: []string{"blog:review", "blog:publish"}},
+	},
+	Event: {
+		Update:        {Owner: []string{"event:write"}, Any: []string{"event:review"}},
+		Delete:        {Owner: []string{"event:delete"}, Any: []string{"event:review"}},
+		History:       {Any: []string{"event:review", "event:publish"}},
+		Registrations: {Owner: []string{"event:write"}, Any: []string{"event:review"}},
+	},
+	Recap: {
+		Update: {Owner: []string{"blog:write"}},
+		Delete: {Owner: []string{"blog:delete"}, Any: []string{"event:review"}},
+	},
+	Dapp: {
+		Update: {Owner: []string{"dapp:write"}, Any: []string{"dapp:review"}},
+		Delete: {Owner: []string{"dapp:delete"}, Any: []string{"dapp:review"}},
+	},
+	User: {
+		Update: {},
+	},
+}
+
+// Authorize 判断 Subject 能否对所有者为 ownerId 的资源执行操作；
+// 只允许所有者操作的规则拒绝时返回 models.ErrNotAuthor，其余返回 utils.ErrForbidden
+func Authorize(s Subject, resource, action string, ownerId uint) error {
+	rule, ok := rules[resource][action]
+	if !ok {
+		return utils.ErrForbidden
+	}
+	switch rule.Scope(s) {
+	case ScopeAny:
+		return nil
+	case ScopeOwn:
+		if s.UserId != 0 && s.UserId == ownerId {
+			return nil
+		}
+	}
+	if len(rule.Any) == 0 && s.UserId != ownerId {
+		return models.ErrNotAuthor
+	}
+	return utils.ErrForbidden
+}
+
+// Capabilities 列出 Subject 对每类资源每个操作的范围，供前端决定展示哪些按钮
+func Capabilities(s Subject) map[string]map[string]Scope {
+	result := make(map[string]map[string]Scope, len(rules))
+	for resource, actions := range rules {
+		result[resource] = make(map[string]Scope, len(actions))
+		for action, rule := range actions {
+			result[resource][action] = rule.Scope(s)
+		}
+	}
+	return result
+}
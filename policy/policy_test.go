@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"hyperlane/models"
+	"hyperlane/utils"
+)
+
+func TestAuthorize(t *testing.T) {
+	writer := Subject{UserId: 1, Permissions: []string{"blog:write", "blog:delete"}}
+	blogAdmin := Subject{UserId: 2, Permissions: []string{"blog:write", "blog:review", "blog:delete", "blog:publish"}}
+	eventCreator := Subject{UserId: 3, Permissions: []string{"event:write"}}
+	eventAdmin := Subject{UserId: 4, Permissions: []string{"event:write", "event:review", "event:delete", "event:publish"}}
+	nobody := Subject{UserId: 5}
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		resource string
+		action   string
+		ownerId  uint
+		wantErr  error
+	}{
+		{name: "Writer deletes own blog", subject: writer, resource: Blog, action: Delete, ownerId: 1},
+		{name: "Writer deletes another blog", subject: writer, resource: Blog, action: Delete, ownerId: 9, wantErr: utils.ErrForbidden},
+		{name: "Blog admin deletes another blog", subject: blogAdmin, resource: Blog, action: Delete, ownerId: 9},
+		{name: "Blog admin edits another blog", subject: blogAdmin, resource: Blog, action: Update, ownerId: 9, wantErr: models.ErrNotAuthor},
+		{name: "Owner without permission", subject: nobody, resource: Blog, action: Update, ownerId: 5, wantErr: utils.ErrForbidden},
+		{name: "Event creator edits own event", subject: eventCreator, resource: Event, action: Update, ownerId: 3},
+		{name: "Event creator edits another event", subject: eventCreator, resource: Event, action: Update, ownerId: 9, wantErr: utils.ErrForbidden},
+		{name: "Event creator deletes own event", subject: eventCreator, resource: Event, action: Delete, ownerId: 3, wantErr: utils.ErrForbidden},
+		{name: "Event admin edits another event", subject: eventAdmin, resource: Event, action: Update, ownerId: 9},
+		{name: "Organizer views registrations", subject: eventCreator, resource: Event, action: Registrations, ownerId: 3},
+		{name: "Organizer without event:write", subject: nobody, resource: Event, action: Registrations, ownerId: 5, wantErr: utils.ErrForbidden},
+		{name: "Organizer views another event registrations", subject: eventCreator, resource: Event, action: Registrations, ownerId: 9, wantErr: utils.ErrForbidden},
+		{name: "Event admin views registrations", subject: eventAdmin, resource: Event, action: Registrations, ownerId: 9},
+		{name: "Event admin deletes recap", subject: eventAdmin, resource: Recap, action: Delete, ownerId: 9},
+		{name: "Comment author", subject: nobody, resource: Comment, action: Delete, ownerId: 5},
+		{name: "Writer deletes another comment", subject: writer, resource: Comment, action: Delete, ownerId: 9, wantErr: utils.ErrForbidden},
+		{name: "Blog admin deletes another comment", subject: blogAdmin, resource: Comment, action: Delete, ownerId: 9},
+		{name: "Event admin deletes another comment", subject: eventAdmin, resource: Comment, action: Delete, ownerId: 9},
+		{name: "Blog admin edits another comment", subject: blogAdmin, resource: Comment, action: Update, ownerId: 9, wantErr: models.ErrNotAuthor},
+		{name: "Anonymous", subject: Subject{}, resource: Comment, action: Update, ownerId: 0, wantErr: utils.ErrForbidden},
+		{name: "Unknown action", subject: blogAdmin, resource: Blog, action: "publish", ownerId: 2, wantErr: utils.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.subject, tt.resource, tt.action, tt.ownerId)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Authorize() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	caps := Capabilities(Subject{UserId: 1, Permissions: []string{"blog:write", "blog:delete", "event:review"}})
+
+	tests := []struct {
+		resource string
+		action   string
+		want     Scope
+	}{
+		{Blog, Update, ScopeOwn},
+		{Blog, Delete, ScopeOwn},
+		{Event, Update, ScopeAny},
+		{Event, Delete, ScopeAny},
+		{Dapp, Update, ScopeNone},
+		{Comment, Update, ScopeOwn},
+		{Comment, Delete, ScopeAny},
+	}
+
+	for _, tt := range tests {
+		if got := caps[tt.resource][tt.action]; got != tt.want {
+			t.Errorf("Capabilities()[%s][%s] = %q, want %q", tt.resource, tt.action, got, tt.want)
+		}
+	}
+}
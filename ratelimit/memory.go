@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 超过该时间未访问的桶已经补满，可以丢弃
+const memoryIdleTTL = 10 * time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Memory 进程内存储，只在单实例内生效
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (m *Memory) Take(_ context.Context, key string, p Policy) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: p.capacity(), last: now}
+		m.buckets[key] = b
+	}
+	tokens, allowed := refill(b.tokens, now.Sub(b.last), p)
+	b.tokens, b.last = tokens, now
+	return result(tokens, allowed, p), nil
+}
+
+// 定期清理长时间未访问的桶，避免按 IP 计数时内存无限增长
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		if now.Sub(b.last) > memoryIdleTTL {
+			delete(m.buckets, key)
+		}
+	}
+}
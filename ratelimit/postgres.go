@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 补充后的令牌数，SET 中的 r.* 取冲突行的旧值
+const refilledSQL = "LEAST(@capacity, r.tokens + GREATEST(EXTRACT(EPOCH FROM now() - r.updated_at), 0) * @rate)"
+
+// 一条语句完成补充和扣减：冲突行加锁保证多副本并发时计数准确，时间统一取数据库时钟
+var takeSQL = strings.ReplaceAll(`INSERT INTO rate_limits AS r (key, tokens, allowed, updated_at)
+VALUES (@key, @capacity - 1, true, now())
+ON CONFLICT (key) DO UPDATE SET
+	tokens = CASE WHEN {refilled} >= 1 THEN {refilled} - 1 ELSE {refilled} END,
+	allowed = {refilled} >= 1,
+	updated_at = now()
+RETURNING r.tokens, r.allowed`, "{refilled}", refilledSQL)
+
+// Postgres 基于 rate_limits 表的存储，多个副本共享同一份计数
+type Postgres struct {
+	db *gorm.DB
+}
+
+func NewPostgres(db *gorm.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+func (s *Postgres) Take(ctx context.Context, key string, p Policy) (Result, error) {
+	var row struct {
+		Tokens  float64
+		Allowed bool
+	}
+	err := s.db.WithContext(ctx).Raw(takeSQL, map[string]interface{}{
+		"key":      key,
+		"capacity": p.capacity(),
+		"rate":     p.rate(),
+	}).Scan(&row).Error
+	if err != nil {
+		return Result{}, err
+	}
+	return result(row.Tokens, row.Allowed, p), nil
+}
+
+// DeleteIdle 删除 before 之后未再访问的记录，这些桶早已补满
+func (s *Postgres) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	res := s.db.WithContext(ctx).Exec("DELETE FROM rate_limits WHERE updated_at < ?", before)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// Policy 令牌桶参数：桶容量为 Burst，每 Period 补充 Limit 个令牌
+type Policy struct {
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+// 每秒补充的令牌数
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+func (p Policy) capacity() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return float64(p.Limit)
+}
+
+func (p Policy) Valid() bool {
+	return p.Limit > 0 && p.Period > 0
+}
+
+// Result 一次取令牌的结果，用于输出 X-RateLimit-* 响应头
+type Result struct {
+	Allowed    bool
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余令牌
+	RetryAfter time.Duration // 被拒绝时距离下一个令牌的时间
+	Reset      time.Duration // 桶补满所需时间
+}
+
+// Store 令牌桶状态存储，key 由策略名和调用方标识组成
+type Store interface {
+	Take(ctx context.Context, key string, p Policy) (Result, error)
+}
+
+// refill 按经过的时间补充令牌并尝试取走一个，返回新的令牌数和是否放行
+func refill(tokens float64, elapsed time.Duration, p Policy) (float64, bool) {
+	if elapsed > 0 {
+		tokens = math.Min(p.capacity(), tokens+elapsed.Seconds()*p.rate())
+	}
+	if tokens >= 1 {
+		return tokens - 1, true
+	}
+	return tokens, false
+}
+
+func result(tokens float64, allowed bool, p Policy) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     int(p.capacity()),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((p.capacity() - tokens) / p.rate()),
+	}
+	if !allowed {
+		r.RetryAfter = secondsToDuration((1 - tokens) / p.rate())
+	}
+	return r
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+var current Store
+
+// Init 按 rateLimit.store 初始化存储：memory（默认，单实例）或 postgres（多副本共享）
+func Init(db *gorm.DB) error {
+	switch store := viper.GetString("rateLimit.store"); store {
+	case "", "memory":
+		current = NewMemory()
+	case "postgres":
+		current = NewPostgres(db)
+	default:
+		return fmt.Errorf("unknown rate limit store: %s", store)
+	}
+	return nil
+}
+
+// Default 返回 Init 初始化的存储
+func Default() Store {
+	return current
+}
+
+// Rule 路由限流策略，Path 为 gin 路由模板（如 /api/v1/posts/:id/like），Method 为空时匹配所有方法；
+// Limit 为 0 表示该路由不限流
+type Rule struct {
+	Name   string
+	Method string
+	Path   string
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+func (r Rule) Policy() Policy {
+	return Policy{Limit: r.Limit, Period: r.Period, Burst: r.Burst}
+}
+
+// Rules 默认策略和按路由配置的策略
+type Rules struct {
+	Default Rule
+	Routes  []Rule
+}
+
+// LoadRules 读取 rateLimit.default 和 rateLimit.routes
+func LoadRules() (*Rules, error) {
+	var rules Rules
+	if err := viper.UnmarshalKey("rateLimit.default", &rules.Default); err != nil {
+		return nil, fmt.Errorf("rateLimit.default: %w", err)
+	}
+	rules.Default.Name = "default"
+	if err := viper.UnmarshalKey("rateLimit.routes", &rules.Routes); err != nil {
+		return nil, fmt.Errorf("rateLimit.routes: %w", err)
+	}
+	for i, r := range rules.Routes {
+		if r.Path == "" {
+			return nil, fmt.Errorf("rateLimit.routes[%d]: path is required", i)
+		}
+		if r.Limit > 0 && r.Period <= 0 {
+			return nil, fmt.Errorf("rateLimit.routes[%d]: period is required", i)
+		}
+		if r.Name == "" {
+			rules.Routes[i].Name = strings.TrimSpace(r.Method + " " + r.Path)
+		}
+	}
+	return &rules, nil
+}
+
+// Match 返回请求对应的策略，没有路由策略时使用默认策略
+func (rs *Rules) Match(method, path string) Rule {
+	for _, r := range rs.Routes {
+		if r.Path == path && (r.Method == "" || strings.EqualFold(r.Method, method)) {
+			return r
+		}
+	}
+	return rs.Default
+}
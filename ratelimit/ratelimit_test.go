@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestMemoryTake(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	policy := Policy{Limit: 2, Period: time.Second, Burst: 3}
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		key       string
+		allowed   bool
+		remaining int
+		retry     time.Duration
+	}{
+		{name: "First request", key: "a", allowed: true, remaining: 2},
+		{name: "Second request", key: "a", allowed: true, remaining: 1},
+		{name: "Burst exhausted", key: "a", allowed: true, remaining: 0},
+		{name: "Rejected", key: "a", allowed: false, remaining: 0, retry: 500 * time.Millisecond},
+		{name: "Other key independent", key: "b", allowed: true, remaining: 2},
+		{name: "Refilled after half second", advance: 500 * time.Millisecond, key: "a", allowed: true, remaining: 0},
+		{name: "Refill capped at burst", advance: time.Hour, key: "a", allowed: true, remaining: 2},
+	}
+
+	for _, s := range steps {
+		t.Run(s.name, func(t *testing.T) {
+			now = now.Add(s.advance)
+			res, err := m.Take(context.Background(), s.key, policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Allowed != s.allowed || res.Remaining != s.remaining || res.RetryAfter != s.retry {
+				t.Errorf("Take() = %+v, want allowed=%v remaining=%d retry=%s", res, s.allowed, s.remaining, s.retry)
+			}
+			if res.Limit != 3 {
+				t.Errorf("Limit = %d, want 3", res.Limit)
+			}
+		})
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	policy := Policy{Limit: 1, Period: time.Second}
+
+	m.Take(context.Background(), "idle", policy)
+	now = now.Add(memoryIdleTTL + time.Minute)
+	m.Take(context.Background(), "active", policy)
+
+	if _, ok := m.buckets["idle"]; ok {
+		t.Error("idle bucket not removed")
+	}
+	if _, ok := m.buckets["active"]; !ok {
+		t.Error("active bucket removed")
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+rateLimit:
+  default: { limit: 100, period: 1m }
+  routes:
+    - { method: POST, path: /api/v1/login, limit: 5, period: 1m, burst: 2 }
+    - { path: /healthz, limit: 0 }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules()
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		rule   string
+		policy Policy
+	}{
+		{name: "Route policy", method: "POST", path: "/api/v1/login", rule: "POST /api/v1/login", policy: Policy{Limit: 5, Period: time.Minute, Burst: 2}},
+		{name: "Method mismatch uses default", method: "GET", path: "/api/v1/login", rule: "default", policy: Policy{Limit: 100, Period: time.Minute}},
+		{name: "Any method", method: "GET", path: "/healthz", rule: "/healthz", policy: Policy{}},
+		{name: "Unmatched route", method: "GET", path: "", rule: "default", policy: Policy{Limit: 100, Period: time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rules.Match(tt.method, tt.path)
+			if r.Name != tt.rule || r.Policy() != tt.policy {
+				t.Errorf("Match() = %s %+v, want %s %+v", r.Name, r.Policy(), tt.rule, tt.policy)
+			}
+		})
+	}
+
+	if (Policy{}).Valid() {
+		t.Error("zero policy should not limit")
+	}
+}
@@ -2,19 +2,28 @@ package routes
 
 import (
 	"hyperlane/controllers"
+	"hyperlane/metrics"
 	"hyperlane/middlewares"
+	"hyperlane/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 )
 
 func SetupRouter(r *gin.Engine) {
-	r.Use(middlewares.Cors())
+	r.GET("/healthz", controllers.Healthz)
+	r.GET("/readyz", controllers.Readyz)
+	r.GET("/metrics", middlewares.MetricsAuth(viper.GetString("metrics.token"), viper.GetStringSlice("metrics.allowedIPs")), gin.WrapH(metrics.Handler()))
 
 	api := r.Group("/api")
 	{
 		api.GET("/v1/auth/callback", controllers.HandleOAuthCallback)
 
 		api.POST("/v1/login", controllers.HandleLogin)
+		api.POST("/v1/auth/exchange", controllers.HandleLoginCodeExchange)
+		api.POST("/v1/auth/refresh", controllers.HandleRefresh)
+		api.POST("/v1/auth/logout", middlewares.JWT(""), controllers.HandleLogout)
+		api.POST("/v1/auth/logout_all", middlewares.JWT(""), controllers.HandleLogoutAll)
 
 		user := api.Group("/v1/users")
 		{
@@ -28,26 +37,51 @@ func SetupRouter(r *gin.Engine) {
 		event := api.Group("/v1/events")
 		{
 			event.POST("", middlewares.JWT("event:write"), controllers.CreateEvent)
-			event.DELETE("/:id", middlewares.JWT("event:delete"), controllers.DeleteEvent)
-			event.PUT("/:id", middlewares.JWT("event:write"), controllers.UpdateEvent)
+			event.DELETE("/:id", middlewares.JWT(""), controllers.DeleteEvent)
+			event.PUT("/:id", middlewares.JWT(""), controllers.UpdateEvent)
 			event.GET("", controllers.QueryEvents)
-			event.GET("/:id", controllers.GetEvent)
-			event.PUT("/:id/status", middlewares.JWT("event:review"), controllers.UpdateEventPublishStatus)
+			event.GET("/calendar.ics", controllers.EventCalendar)
+			event.GET("/:id", middlewares.OptionalJWT(), controllers.GetEvent) // 也处理 /:id.ics
+			event.PUT("/:id/status", middlewares.JWT(""), controllers.TransitionContent(models.CommentTargetEvent))
+			event.GET("/:id/reviews", middlewares.JWT(""), controllers.QueryContentReviews(models.CommentTargetEvent))
 
 			// 发布博客是用户默认权限， 这里任何用户都可以添加recap
 			event.POST("/recap", middlewares.JWT("blog:write"), controllers.CreateReacp)
-			event.DELETE("/recap/:id", middlewares.JWT("blog:delete"), controllers.DeleteRecap)
-			event.PUT("/recap/:id", middlewares.JWT("blog:write"), controllers.UpdateRecap)
+			event.DELETE("/recap/:id", middlewares.JWT(""), controllers.DeleteRecap)
+			event.PUT("/recap/:id", middlewares.JWT(""), controllers.UpdateRecap)
 			event.GET("/recap", controllers.GetRecap)
+
+			event.POST("/:id/registrations", middlewares.JWT(""), controllers.RegisterEvent)
+			event.DELETE("/:id/registrations", middlewares.JWT(""), controllers.CancelEventRegistration)
+			event.GET("/:id/registrations/me", middlewares.JWT(""), controllers.GetMyEventRegistration)
+			event.GET("/:id/registrations", middlewares.JWT(""), controllers.QueryEventRegistrations)
+			event.GET("/:id/registrations/export", middlewares.JWT(""), controllers.ExportEventRegistrations)
+
+			event.GET("/:id/comments", controllers.QueryComments(models.CommentTargetEvent))
+			event.POST("/:id/comments", middlewares.JWT(""), controllers.CreateComment(models.CommentTargetEvent))
+			event.PUT("/:id/comments/:comment_id", middlewares.JWT(""), controllers.UpdateComment(models.CommentTargetEvent))
+			event.DELETE("/:id/comments/:comment_id", middlewares.JWT(""), controllers.DeleteComment(models.CommentTargetEvent))
 		}
 		blog := api.Group("/v1/blogs")
 		{
 			blog.POST("", middlewares.JWT("blog:write"), controllers.CreateArticle)
-			blog.DELETE("/:id", middlewares.JWT("blog:delete"), controllers.DeleteArticle)
-			blog.PUT("/:id", middlewares.JWT("blog:write"), controllers.UpdateArticle)
-			blog.GET("/:id", controllers.GetArticle)
+			blog.DELETE("/:id", middlewares.JWT(""), controllers.DeleteArticle)
+			blog.PUT("/:id", middlewares.JWT(""), controllers.UpdateArticle)
+			blog.GET("/:id", middlewares.OptionalJWT(), controllers.GetArticle)
 			blog.GET("", controllers.QueryArticles)
-			blog.PUT("/:id/status", middlewares.JWT("blog:review"), controllers.UpdateArticlePublishStatus)
+			blog.PUT("/:id/status", middlewares.JWT(""), controllers.TransitionContent(models.CommentTargetBlog))
+			blog.GET("/:id/reviews", middlewares.JWT(""), controllers.QueryContentReviews(models.CommentTargetBlog))
+			blog.GET("/:id/revisions", middlewares.JWT(""), controllers.QueryArticleRevisions)
+			blog.GET("/:id/revisions/:revision_id", middlewares.JWT(""), controllers.GetArticleRevision)
+			blog.GET("/:id/revisions/:revision_id/diff", middlewares.JWT(""), controllers.DiffArticleRevision)
+			blog.POST("/:id/revisions/:revision_id/restore", middlewares.JWT(""), controllers.RestoreArticleRevision)
+			blog.POST("/:id/revisions/:revision_id/approve", middlewares.JWT("blog:publish"), controllers.ReviewArticleRevision(true))
+			blog.POST("/:id/revisions/:revision_id/reject", middlewares.JWT("blog:review"), controllers.ReviewArticleRevision(false))
+
+			blog.GET("/:id/comments", controllers.QueryComments(models.CommentTargetBlog))
+			blog.POST("/:id/comments", middlewares.JWT(""), controllers.CreateComment(models.CommentTargetBlog))
+			blog.PUT("/:id/comments/:comment_id", middlewares.JWT(""), controllers.UpdateComment(models.CommentTargetBlog))
+			blog.DELETE("/:id/comments/:comment_id", middlewares.JWT(""), controllers.DeleteComment(models.CommentTargetBlog))
 		}
 		feedback := api.Group("/v1/feedbacks")
 		{
@@ -57,17 +91,79 @@ func SetupRouter(r *gin.Engine) {
 		post := api.Group("/v1/posts")
 		{
 			post.POST("", middlewares.JWT("blog:write"), controllers.CreatePost)
-			post.DELETE("/:id", middlewares.JWT("blog:delete"), controllers.DeletePost)
-			post.GET("/:id", controllers.GetPost)
-			post.PUT("/:id", middlewares.JWT("blog:write"), controllers.UpdatePost)
-			post.GET("", controllers.QueryPosts)
+			post.DELETE("/:id", middlewares.JWT(""), controllers.DeletePost)
+			post.GET("/:id", middlewares.OptionalJWT(), controllers.GetPost)
+			post.PUT("/:id", middlewares.JWT(""), controllers.UpdatePost)
+			post.GET("", middlewares.OptionalJWT(), controllers.QueryPosts)
 			post.GET("/stats", controllers.PostsStats)
 			post.POST("/:id/like", middlewares.JWT(""), controllers.LikePost)
 			post.POST("/:id/unlike", middlewares.JWT(""), controllers.UnlikePost)
 			post.POST("/:id/favorite", middlewares.JWT(""), controllers.FavoritePost)
 			post.POST("/:id/unfavorite", middlewares.JWT(""), controllers.UnfavoritePost)
 			post.GET("/status", middlewares.JWT(""), controllers.GetPostStatus)
+
+			post.GET("/:id/comments", controllers.QueryComments(models.CommentTargetPost))
+			post.POST("/:id/comments", middlewares.JWT(""), controllers.CreateComment(models.CommentTargetPost))
+			post.PUT("/:id/comments/:comment_id", middlewares.JWT(""), controllers.UpdateComment(models.CommentTargetPost))
+			post.DELETE("/:id/comments/:comment_id", middlewares.JWT(""), controllers.DeleteComment(models.CommentTargetPost))
+		}
+		dapp := api.Group("/v1/dapps")
+		{
+			dapp.POST("", middlewares.JWT("dapp:write"), controllers.CreateDapp)
+			dapp.DELETE("/:id", middlewares.JWT(""), controllers.DeleteDapp)
+			dapp.PUT("/:id", middlewares.JWT(""), controllers.UpdateDapp)
+			dapp.GET("/:id", controllers.GetDapp)
+			dapp.GET("", middlewares.OptionalJWT(), controllers.QueryDapps)
+			dapp.PUT("/:id/status", middlewares.JWT("dapp:publish"), controllers.UpdateDappPublishStatus)
+		}
+		notification := api.Group("/v1/notifications")
+		{
+			notification.GET("", middlewares.JWT(""), controllers.QueryNotifications)
+			notification.GET("/unread_count", middlewares.JWT(""), controllers.GetUnreadNotificationCount)
+			notification.PUT("/:id/read", middlewares.JWT(""), controllers.MarkNotificationRead)
+			notification.PUT("/read_all", middlewares.JWT(""), controllers.MarkAllNotificationsRead)
+		}
+		upload := api.Group("/v1/uploads", middlewares.JWT(""))
+		{
+			upload.POST("", controllers.UploadImage)
+			upload.GET("", controllers.QueryUploads)
+			upload.DELETE("/:id", controllers.DeleteUpload)
+		}
+		admin := api.Group("/v1/admin", middlewares.JWT("rbac:manage"))
+		{
+			admin.GET("/permissions", controllers.ListPermissions)
+			admin.GET("/roles", controllers.ListRoles)
+			admin.POST("/roles", controllers.CreateRole)
+			admin.PUT("/roles/:id", controllers.UpdateRole)
+			admin.POST("/roles/:id/permissions/:permission_id", controllers.SetRolePermission(true))
+			admin.DELETE("/roles/:id/permissions/:permission_id", controllers.SetRolePermission(false))
+			admin.POST("/roles/:id/groups/:group_id", controllers.SetRolePermissionGroup(true))
+			admin.DELETE("/roles/:id/groups/:group_id", controllers.SetRolePermissionGroup(false))
+			admin.GET("/groups", controllers.ListPermissionGroups)
+			admin.POST("/groups", controllers.CreatePermissionGroup)
+			admin.PUT("/groups/:id", controllers.UpdatePermissionGroup)
+			admin.POST("/groups/:id/permissions/:permission_id", controllers.SetPermissionGroupPermission(true))
+			admin.DELETE("/groups/:id/permissions/:permission_id", controllers.SetPermissionGroupPermission(false))
+			admin.PUT("/users/:id/role", controllers.AssignUserRole)
+			admin.GET("/audit_logs", controllers.QueryAuditLogs)
+			admin.GET("/jobs/runs", controllers.QueryJobRuns)
+		}
+		moderation := api.Group("/v1/moderation", middlewares.JWT(""))
+		{
+			moderation.GET("/queue", controllers.QueryModerationQueue)
+			moderation.GET("/stats", controllers.ModerationStats)
+			moderation.POST("/bulk", controllers.BulkModerate)
+			moderation.POST("/:target_type/:id/claim", controllers.ClaimModerationItem)
+			moderation.DELETE("/:target_type/:id/claim", controllers.UnclaimModerationItem)
+		}
+		feed := api.Group("/v1/feeds")
+		{
+			feed.GET("/blogs/:format", controllers.BlogFeed)
+			feed.GET("/events/:format", controllers.EventFeed)
+			feed.GET("/users/:id/:format", controllers.UserActivityFeed)
 		}
+		api.GET("/v1/search", controllers.Search)
 		api.GET("/v1/stats", controllers.StatsOverview)
+		api.GET("/v1/me/permissions", middlewares.JWT(""), controllers.MyPermissions)
 	}
 }
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetupRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	SetupRouter(r)
+
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/v1/login"},
+		{http.MethodPost, "/api/v1/auth/exchange"},
+		{http.MethodPost, "/api/v1/auth/refresh"},
+		{http.MethodGet, "/api/v1/events/:id"},
+		{http.MethodPost, "/api/v1/events/:id/registrations"},
+		{http.MethodGet, "/api/v1/blogs/:id/comments"},
+		{http.MethodGet, "/api/v1/posts/stats"},
+		{http.MethodGet, "/api/v1/dapps"},
+		{http.MethodGet, "/api/v1/search"},
+		{http.MethodGet, "/api/v1/me/permissions"},
+		{http.MethodGet, "/api/v1/moderation/queue"},
+		{http.MethodGet, "/api/v1/admin/jobs/runs"},
+		{http.MethodPost, "/api/v1/moderation/:target_type/:id/claim"},
+		{http.MethodGet, "/api/v1/feeds/blogs/:format"},
+		{http.MethodGet, "/api/v1/events/calendar.ics"},
+		{http.MethodGet, "/healthz"},
+		{http.MethodGet, "/readyz"},
+		{http.MethodGet, "/metrics"},
+	}
+
+	for _, tt := range tests {
+		if !registered[tt.method+" "+tt.path] {
+			t.Errorf("route %s %s not registered", tt.method, tt.path)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config S3 兼容存储配置（AWS S3、MinIO、R2 等）
+type S3Config struct {
+	Endpoint  string // 不带协议的地址，如 s3.amazonaws.com、localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool   // 使用 endpoint/bucket/key 形式访问，MinIO 需要开启
+	BaseURL   string // 公开访问地址（如 CDN），为空时使用 endpoint 拼接
+
+	Transport http.RoundTripper // 自定义传输层，测试时使用
+}
+
+// S3 S3 兼容对象存储
+type S3 struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("storage.s3.endpoint and storage.s3.bucket are required")
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+		Transport:    cfg.Transport,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		scheme := "http"
+		if cfg.UseSSL {
+			scheme = "https"
+		}
+		if cfg.PathStyle {
+			baseURL = fmt.Sprintf("%s://%s/%s", scheme, cfg.Endpoint, cfg.Bucket)
+		} else {
+			baseURL = fmt.Sprintf("%s://%s.%s", scheme, cfg.Bucket, cfg.Endpoint)
+		}
+	}
+	return &S3{client: client, bucket: cfg.Bucket, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	return err
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3) URL(key string) string {
+	return s.baseURL + "/" + key
+}
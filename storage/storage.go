@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// Storage 对象存储接口，key 为不以 / 开头的相对路径，如 images/ab/abcd.jpg
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// URL 返回对象的公开访问地址
+	URL(key string) string
+}
+
+var ErrInvalidKey = errors.New("invalid object key")
+
+var current Storage
+
+// Init 按 storage.driver 初始化存储，支持 local（默认）和 s3
+func Init() error {
+	var err error
+	switch driver := viper.GetString("storage.driver"); driver {
+	case "", "local":
+		current, err = NewLocal(viper.GetString("storage.local.dir"), viper.GetString("storage.local.baseUrl"))
+	case "s3":
+		current, err = NewS3(S3Config{
+			Endpoint:  viper.GetString("storage.s3.endpoint"),
+			Region:    viper.GetString("storage.s3.region"),
+			Bucket:    viper.GetString("storage.s3.bucket"),
+			AccessKey: viper.GetString("storage.s3.accessKey"),
+			SecretKey: viper.GetString("storage.s3.secretKey"),
+			UseSSL:    viper.GetBool("storage.s3.useSsl"),
+			PathStyle: viper.GetBool("storage.s3.pathStyle"),
+			BaseURL:   viper.GetString("storage.s3.baseUrl"),
+		})
+	default:
+		err = fmt.Errorf("unknown storage driver: %s", driver)
+	}
+	return err
+}
+
+// Default 返回 Init 初始化的存储
+func Default() Storage {
+	return current
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocal(dir, "https://cdn.example.com/files/")
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "Nested key", key: "images/ab/abcd.png", wantErr: false},
+		{name: "Parent traversal", key: "../escape.png", wantErr: true},
+		{name: "Absolute path", key: "/etc/passwd", wantErr: true},
+		{name: "Empty key", key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Put(ctx, tt.key, strings.NewReader("data"), 4, "image/png")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.key)))
+			if err != nil || string(got) != "data" {
+				t.Fatalf("stored content = %q, %v", got, err)
+			}
+			if url := s.URL(tt.key); url != "https://cdn.example.com/files/"+tt.key {
+				t.Errorf("URL() = %s", url)
+			}
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Errorf("Delete() of missing object error = %v", err)
+			}
+		})
+	}
+}
+
+// fakeS3 最小的 S3 兼容服务，只支持单次 PUT / DELETE
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = data
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	srv := httptest.NewTLSServer(fake)
+	defer srv.Close()
+
+	s, err := NewS3(S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "https://"),
+		Region:    "us-east-1",
+		Bucket:    "media",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+		UseSSL:    true,
+		PathStyle: true,
+		Transport: srv.Client().Transport,
+	})
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+	ctx := context.Background()
+
+	content := []byte("image-bytes")
+	if err := s.Put(ctx, "images/ab/abcd.png", bytes.NewReader(content), int64(len(content)), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := fake.objects["/media/images/ab/abcd.png"]; !bytes.Equal(got, content) {
+		t.Fatalf("stored content = %q", got)
+	}
+	if got := fake.types["/media/images/ab/abcd.png"]; got != "image/png" {
+		t.Errorf("stored content type = %q", got)
+	}
+	if url := s.URL("images/ab/abcd.png"); url != srv.URL+"/media/images/ab/abcd.png" {
+		t.Errorf("URL() = %s", url)
+	}
+
+	if err := s.Delete(ctx, "images/ab/abcd.png"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := fake.objects["/media/images/ab/abcd.png"]; ok {
+		t.Error("object still exists after Delete()")
+	}
+}
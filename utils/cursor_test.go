@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	InitCursor("test-secret")
+	at := time.Date(2025, 3, 1, 8, 30, 15, 123456000, time.UTC)
+	valid := EncodeCursor(Cursor{Scope: "posts", Time: at, ID: 42, Desc: true})
+
+	payload, sig, _ := strings.Cut(valid, ".")
+	tampered := payload[:len(payload)-2] + "xy." + sig
+
+	InitCursor("other-secret")
+	otherSecret := EncodeCursor(Cursor{Scope: "posts", Time: at, ID: 42, Desc: true})
+	InitCursor("test-secret")
+
+	tests := []struct {
+		name    string
+		cursor  string
+		scope   string
+		wantErr bool
+	}{
+		{name: "Valid", cursor: valid, scope: "posts"},
+		{name: "Wrong scope", cursor: valid, scope: "events", wantErr: true},
+		{name: "Tampered payload", cursor: tampered, scope: "posts", wantErr: true},
+		{name: "Signed with another secret", cursor: otherSecret, scope: "posts", wantErr: true},
+		{name: "Missing signature", cursor: payload, scope: "posts", wantErr: true},
+		{name: "Garbage", cursor: "not-a-cursor", scope: "posts", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.cursor, tt.scope)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Fatalf("DecodeCursor() error = %v, want ErrInvalidCursor", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Time.Equal(at) || got.ID != 42 || !got.Desc || got.Scope != "posts" {
+				t.Errorf("DecodeCursor() = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package utils
+
+import "strings"
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// 编辑距离超过该值时不再计算最短差异，直接输出整段删除和插入，避免大文本占用过多内存
+const maxDiffEdits = 2000
+
+type DiffLine struct {
+	Op   string `json:"op"` // equal / insert / delete
+	Text string `json:"text"`
+}
+
+// DiffLines 按行比较两段文本（Myers 算法），返回从 a 变为 b 的逐行差异
+func DiffLines(a, b string) []DiffLine {
+	x, y := splitLines(a), splitLines(b)
+
+	// 去掉公共前后缀，缩小比较范围
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	var diff []DiffLine
+	for _, line := range x[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	diff = append(diff, myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, line := range x[len(x)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+}
+
+func myers(x, y []string) []DiffLine {
+	n, m := len(x), len(y)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	limit := n + m
+	if limit > maxDiffEdits {
+		limit = maxDiffEdits
+	}
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] 保存第 d 轮开始前 k ∈ [-d-1, d+1] 的最远位置，用于回溯
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		diff := make([]DiffLine, 0, n+m)
+		for _, line := range x {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range y {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// 从终点回溯出编辑路径，结果是倒序的
+	var reversed []DiffLine
+	i, j := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d+1] }
+
+		k := i - j
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevI := at(prevK)
+		prevJ := prevI - prevK
+
+		for i > prevI && j > prevJ {
+			reversed = append(reversed, DiffLine{Op: DiffEqual, Text: x[i-1]})
+			i--
+			j--
+		}
+		if d > 0 {
+			if i == prevI {
+				reversed = append(reversed, DiffLine{Op: DiffInsert, Text: y[j-1]})
+			} else {
+				reversed = append(reversed, DiffLine{Op: DiffDelete, Text: x[i-1]})
+			}
+		}
+		i, j = prevI, prevJ
+	}
+
+	diff := make([]DiffLine, len(reversed))
+	for idx, line := range reversed {
+		diff[len(reversed)-1-idx] = line
+	}
+	return diff
+}
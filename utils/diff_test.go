@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string // 每行一个操作：空格为相同，+ 为插入，- 为删除
+	}{
+		{name: "Identical", a: "a\nb\nc", b: "a\nb\nc", expected: " a\n b\n c"},
+		{name: "Both empty", a: "", b: "", expected: ""},
+		{name: "Insert into empty", a: "", b: "a\nb", expected: "+a\n+b"},
+		{name: "Delete all", a: "a\nb\n", b: "", expected: "-a\n-b"},
+		{name: "Replace middle line", a: "a\nb\nc", b: "a\nx\nc", expected: " a\n-b\n+x\n c"},
+		{name: "Insert and delete", a: "a\nb\nc\nd", b: "b\nc\ne\nd", expected: "-a\n b\n c\n+e\n d"},
+		{name: "CRLF normalized", a: "a\r\nb\r\n", b: "a\nb\nc\n", expected: " a\n b\n+c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []string
+			for _, d := range DiffLines(tt.a, tt.b) {
+				prefix := map[string]string{DiffEqual: " ", DiffInsert: "+", DiffDelete: "-"}[d.Op]
+				lines = append(lines, prefix+d.Text)
+			}
+			if got := strings.Join(lines, "\n"); got != tt.expected {
+				t.Errorf("DiffLines() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffLinesReconstruct(t *testing.T) {
+	a := strings.Repeat("same\nold\n", 50) + "tail"
+	b := "head\n" + strings.Repeat("same\nnew\nextra\n", 40) + "tail"
+
+	var before, after []string
+	for _, d := range DiffLines(a, b) {
+		if d.Op != DiffInsert {
+			before = append(before, d.Text)
+		}
+		if d.Op != DiffDelete {
+			after = append(after, d.Text)
+		}
+	}
+	if strings.Join(before, "\n") != a || strings.Join(after, "\n") != b {
+		t.Error("diff does not reconstruct both inputs")
+	}
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// 通用错误码，业务错误使用更具体的码（如 POST_NOT_FOUND）
+const (
+	CodeInvalidArgument  = "INVALID_ARGUMENT"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeForbidden        = "FORBIDDEN"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeTooManyRequests  = "RATE_LIMITED"
+	CodeInternal         = "INTERNAL_ERROR"
+	CodeUnavailable      = "SERVICE_UNAVAILABLE"
+)
+
+// AppError 返回给客户端的错误：Status 为 HTTP 状态码，Code 为稳定的错误码，客户端应据此判断而不是解析 Message
+type AppError struct {
+	Status  int
+	Code    string
+	Message string      // 默认（英文）提示，可被消息目录中的翻译替换
+	Details interface{} // 附加信息，如参数校验失败的字段
+	cause   error       // 原始错误，只用于日志
+}
+
+func NewAppError(status int, code, message string) *AppError {
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+func (e *AppError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.cause)
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
+// Is 按错误码比较，WithDetails / Wrap 得到的副本与原错误视为同一错误
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t != nil && t.Code == e.Code
+}
+
+// WithDetails 返回附带详细信息的副本，不修改预定义的错误
+func (e *AppError) WithDetails(details interface{}) *AppError {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// Wrap 返回记录了原始错误的副本
+func (e *AppError) Wrap(cause error) *AppError {
+	cp := *e
+	cp.cause = cause
+	return &cp
+}
+
+var (
+	ErrInvalidArgument = NewAppError(http.StatusBadRequest, CodeInvalidArgument, "invalid arguments")
+	ErrValidation      = NewAppError(http.StatusBadRequest, CodeValidationFailed, "request validation failed")
+	ErrUnauthorized    = NewAppError(http.StatusUnauthorized, CodeUnauthorized, "please log in to continue")
+	ErrForbidden       = NewAppError(http.StatusForbidden, CodeForbidden, "permission denied")
+	ErrNotFound        = NewAppError(http.StatusNotFound, CodeNotFound, "resource not found")
+	ErrInternal        = NewAppError(http.StatusInternalServerError, CodeInternal, "internal server error")
+	ErrUnavailable     = NewAppError(http.StatusServiceUnavailable, CodeUnavailable, "service unavailable")
+)
+
+// FieldError 参数校验失败的字段
+type FieldError struct {
+	Field string `json:"field"` // JSON 字段名
+	Rule  string `json:"rule"`  // 未通过的校验规则，如 required、max
+	Param string `json:"param,omitempty"`
+}
+
+// ToAppError 把任意错误转换为 AppError：记录不存在为 404，请求体解析和校验失败为 400，其余为 500
+func ToAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound.Wrap(err)
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{Field: fe.Field(), Rule: fe.Tag(), Param: fe.Param()})
+		}
+		return ErrValidation.WithDetails(fields).Wrap(err)
+	case errors.As(err, &typeErr):
+		return ErrValidation.WithDetails([]FieldError{{Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String()}}).Wrap(err)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrInvalidArgument.Wrap(err)
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// RenderError 输出错误响应，message 按 Accept-Language 从消息目录取翻译
+func RenderError(c *gin.Context, err error) {
+	appErr := ToAppError(err)
+	body := gin.H{
+		"code":       appErr.Status,
+		"error_code": appErr.Code,
+		"message":    Localize(c.GetHeader("Accept-Language"), appErr.Code, appErr.Message),
+		"data":       nil,
+	}
+	if appErr.Details != nil {
+		body["details"] = appErr.Details
+	}
+	if id := c.GetString("request_id"); id != "" {
+		body["request_id"] = id
+	}
+	c.AbortWithStatusJSON(appErr.Status, body)
+}
+
+// 没有指定错误码的响应按状态码归类
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeInvalidArgument
+}
+
+// InitValidator 让参数校验错误使用 JSON 字段名，与请求体一致
+func InitValidator() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errPostNotFound = NewAppError(http.StatusNotFound, "POST_NOT_FOUND", "post not found")
+
+func TestToAppError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "App error", err: errPostNotFound, wantStatus: http.StatusNotFound, wantCode: "POST_NOT_FOUND"},
+		{name: "Wrapped app error", err: fmt.Errorf("like: %w", errPostNotFound), wantStatus: http.StatusNotFound, wantCode: "POST_NOT_FOUND"},
+		{name: "Record not found", err: gorm.ErrRecordNotFound, wantStatus: http.StatusNotFound, wantCode: CodeNotFound},
+		{name: "Malformed JSON", err: json.Unmarshal([]byte("{"), &struct{}{}), wantStatus: http.StatusBadRequest, wantCode: CodeInvalidArgument},
+		{name: "Wrong field type", err: json.Unmarshal([]byte(`{"a":"x"}`), &struct{ A int }{}), wantStatus: http.StatusBadRequest, wantCode: CodeValidationFailed},
+		{name: "Unknown error", err: errors.New(`pq: relation "posts" does not exist`), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToAppError(tt.err)
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("ToAppError() = %d %s, want %d %s", got.Status, got.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAppErrorIs(t *testing.T) {
+	wrapped := errPostNotFound.WithDetails("id=1").Wrap(gorm.ErrRecordNotFound)
+	if !errors.Is(wrapped, errPostNotFound) {
+		t.Error("copy should match the original error")
+	}
+	if !errors.Is(wrapped, gorm.ErrRecordNotFound) {
+		t.Error("copy should unwrap to its cause")
+	}
+	if errors.Is(wrapped, ErrNotFound) {
+		t.Error("errors with different codes should not match")
+	}
+	if errPostNotFound.Details != nil {
+		t.Error("WithDetails should not modify the original error")
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		Title string `json:"title" binding:"required"`
+	}
+	InitValidator()
+
+	tests := []struct {
+		name         string
+		handler      gin.HandlerFunc
+		language     string
+		wantStatus   int
+		wantCode     string
+		wantMessage  string
+		wantField    string
+		notInMessage string
+	}{
+		{
+			name:        "App error",
+			handler:     func(c *gin.Context) { RenderError(c, errPostNotFound) },
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "POST_NOT_FOUND",
+			wantMessage: "post not found",
+		},
+		{
+			name:        "Chinese message",
+			handler:     func(c *gin.Context) { RenderError(c, errPostNotFound) },
+			language:    "zh-CN,zh;q=0.9,en;q=0.8",
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "POST_NOT_FOUND",
+			wantMessage: "帖子不存在",
+		},
+		{
+			name:        "English preferred",
+			handler:     func(c *gin.Context) { RenderError(c, errPostNotFound) },
+			language:    "zh;q=0.5,en",
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "POST_NOT_FOUND",
+			wantMessage: "post not found",
+		},
+		{
+			name: "Validation error",
+			handler: func(c *gin.Context) {
+				var req request
+				RenderError(c, c.ShouldBindJSON(&req))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeValidationFailed,
+			wantField:  "title",
+		},
+		{
+			name: "Internal error hides details",
+			handler: func(c *gin.Context) {
+				RenderError(c, errors.New(`pq: password authentication failed for user "hyperlane"`))
+			},
+			wantStatus:   http.StatusInternalServerError,
+			wantCode:     CodeInternal,
+			wantMessage:  "internal server error",
+			notInMessage: "pq:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/", tt.handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.language != "" {
+				req.Header.Set("Accept-Language", tt.language)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body struct {
+				Code      int          `json:"code"`
+				ErrorCode string       `json:"error_code"`
+				Message   string       `json:"message"`
+				Details   []FieldError `json:"details"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantStatus || body.ErrorCode != tt.wantCode {
+				t.Errorf("body = %d %s, want %d %s", body.Code, body.ErrorCode, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantMessage != "" && body.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body.Message, tt.wantMessage)
+			}
+			if tt.wantField != "" && (len(body.Details) != 1 || body.Details[0].Field != tt.wantField) {
+				t.Errorf("details = %+v, want field %s", body.Details, tt.wantField)
+			}
+			if tt.notInMessage != "" && strings.Contains(w.Body.String(), tt.notInMessage) {
+				t.Errorf("body leaks %q: %s", tt.notInMessage, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestErrorResponseCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	ErrorResponse(c, http.StatusForbidden, "permission denied", nil)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["code"] != float64(http.StatusForbidden) || body["error_code"] != CodeForbidden {
+		t.Errorf("body = %v", body)
+	}
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	return &Feed{
+		Title:       "Hyperlane Blogs",
+		Description: "Latest blogs",
+		Link:        "https://example.com/blogs",
+		FeedURL:     "https://api.example.com/api/v1/feeds/blogs/rss",
+		Updated:     published.Add(time.Hour),
+		Items: []FeedItem{
+			{
+				ID:        "https://example.com/blogs/1",
+				Title:     "Hello <world> & friends",
+				Link:      "https://example.com/blogs/1",
+				Summary:   "summary",
+				Content:   "full content",
+				Author:    "alice",
+				Tags:      []string{"go", "web3"},
+				Published: published,
+				Updated:   published.Add(time.Hour),
+			},
+		},
+	}
+}
+
+func TestRenderFeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{
+			name:   "RSS",
+			format: FeedRSS,
+			contains: []string{
+				`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`,
+				`<title>Hello &lt;world&gt; &amp; friends</title>`,
+				`<guid isPermaLink="true">https://example.com/blogs/1</guid>`,
+				`<pubDate>Sat, 01 Mar 2025 08:00:00 +0000</pubDate>`,
+				`<category>web3</category>`,
+			},
+		},
+		{
+			name:   "Atom",
+			format: FeedAtom,
+			contains: []string{
+				`<feed xmlns="http://www.w3.org/2005/Atom">`,
+				`<updated>2025-03-01T09:00:00Z</updated>`,
+				`<published>2025-03-01T08:00:00Z</published>`,
+				`<content type="text">full content</content>`,
+				`<category term="go"></category>`,
+			},
+		},
+		{
+			name:   "JSON Feed",
+			format: FeedJSON,
+			contains: []string{
+				`"version": "https://jsonfeed.org/version/1.1"`,
+				`"content_text": "full content"`,
+				`"date_published": "2025-03-01T08:00:00Z"`,
+			},
+		},
+		{name: "Unknown format", format: "csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := RenderFeed(testFeed(), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderFeed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var v interface{}
+			if tt.format == FeedJSON {
+				err = json.Unmarshal(body, &v)
+			} else {
+				err = xml.Unmarshal(body, &v)
+			}
+			if err != nil {
+				t.Fatalf("output is not well-formed: %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(body), s) {
+					t.Errorf("output missing %s\n%s", s, body)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckNotModified(t *testing.T) {
+	lastModified := time.Date(2025, 3, 1, 8, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected bool
+	}{
+		{name: "No conditional headers", headers: nil, expected: false},
+		{name: "Matching ETag", headers: map[string]string{"If-None-Match": `"abc"`}, expected: true},
+		{name: "Weak ETag in list", headers: map[string]string{"If-None-Match": `"x", W/"abc"`}, expected: true},
+		{name: "Different ETag ignores date", headers: map[string]string{"If-None-Match": `"x"`, "If-Modified-Since": "Sat, 01 Mar 2025 09:00:00 GMT"}, expected: false},
+		{name: "Not modified since", headers: map[string]string{"If-Modified-Since": "Sat, 01 Mar 2025 08:00:00 GMT"}, expected: true},
+		{name: "Modified since", headers: map[string]string{"If-Modified-Since": "Sat, 01 Mar 2025 07:59:59 GMT"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := CheckNotModified(r, `"abc"`, lastModified); got != tt.expected {
+				t.Errorf("CheckNotModified() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
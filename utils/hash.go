@@ -1,6 +1,11 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 // HashPassword 将密码加密（注册时用）
 func HashPassword(password string) (string, error) {
@@ -13,3 +18,39 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// GenerateRefreshToken 生成随机刷新令牌（明文只返回给客户端）
+func GenerateRefreshToken() (string, error) {
+	return randomHex(32)
+}
+
+// GenerateLoginCode 生成 OAuth 回调使用的一次性登录码
+func GenerateLoginCode() (string, error) {
+	return randomHex(32)
+}
+
+// GenerateFamilyId 生成刷新令牌 family 标识
+func GenerateFamilyId() (string, error) {
+	return randomHex(16)
+}
+
+// GenerateRequestId 生成请求 ID，用于串联同一请求的日志
+func GenerateRequestId() string {
+	id, err := randomHex(16)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// HashToken 刷新令牌入库前做 SHA-256
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashContent 计算文件内容的 SHA-256，用于上传去重
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
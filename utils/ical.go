@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Calendar iCalendar（RFC 5545）日历
+type Calendar struct {
+	ProdID          string
+	Name            string         // X-WR-CALNAME，订阅时显示的日历名称
+	Location        *time.Location // DTSTART/DTEND 使用的时区，为空时输出 UTC
+	RefreshInterval time.Duration  // 建议客户端刷新订阅的间隔，0 表示不输出
+	Events          []CalendarEvent
+}
+
+type CalendarEvent struct {
+	UID         string // 同一活动必须保持不变，客户端据此更新已导入的事件
+	Sequence    uint   // 活动每次修改递增
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Categories  []string
+	Start       time.Time
+	End         time.Time
+	Created     time.Time
+	Updated     time.Time
+}
+
+// RenderCalendar 输出 text/calendar 内容，行以 CRLF 结尾并按 75 字节折行
+func RenderCalendar(cal *Calendar) []byte {
+	w := &icalWriter{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + cal.ProdID)
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME:" + escapeICalText(cal.Name))
+	}
+	if cal.RefreshInterval > 0 {
+		w.line("REFRESH-INTERVAL;VALUE=DURATION:" + icalDuration(cal.RefreshInterval))
+		w.line("X-PUBLISHED-TTL:" + icalDuration(cal.RefreshInterval))
+	}
+
+	loc := cal.Location
+	if loc == nil || loc == time.UTC {
+		loc = nil
+	} else {
+		w.line("X-WR-TIMEZONE:" + loc.String())
+		writeVTimezone(w, loc, cal.Events)
+	}
+
+	for _, e := range cal.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + e.UID)
+		w.line("DTSTAMP:" + icalUTC(latest(e.Updated, e.Created)))
+		w.line(icalDateTime("DTSTART", e.Start, loc))
+		w.line(icalDateTime("DTEND", e.End, loc))
+		if !e.Created.IsZero() {
+			w.line("CREATED:" + icalUTC(e.Created))
+		}
+		if !e.Updated.IsZero() {
+			w.line("LAST-MODIFIED:" + icalUTC(e.Updated))
+		}
+		w.line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		w.line("SUMMARY:" + escapeICalText(e.Summary))
+		if e.Description != "" {
+			w.line("DESCRIPTION:" + escapeICalText(e.Description))
+		}
+		if e.Location != "" {
+			w.line("LOCATION:" + escapeICalText(e.Location))
+		}
+		if e.URL != "" {
+			w.line("URL:" + e.URL)
+		}
+		if len(e.Categories) > 0 {
+			categories := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				categories[i] = escapeICalText(c)
+			}
+			w.line("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		w.line("STATUS:CONFIRMED")
+		w.line("END:VEVENT")
+	}
+	w.line("END:VCALENDAR")
+	return []byte(w.String())
+}
+
+type icalWriter struct {
+	strings.Builder
+}
+
+// 内容行超过 75 字节时折行，续行以空格开头，不拆分 UTF-8 字符
+func (w *icalWriter) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // 续行开头的空格占 1 字节
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func escapeICalText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+func icalUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func icalDateTime(name string, t time.Time, loc *time.Location) string {
+	if loc == nil {
+		return name + ":" + icalUTC(t)
+	}
+	return name + ";TZID=" + loc.String() + ":" + t.In(loc).Format("20060102T150405")
+}
+
+func icalDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("P%dD", d/(24*time.Hour))
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+	return fmt.Sprintf("PT%dM", d/time.Minute)
+}
+
+// 输出时区定义：在活动覆盖的年份内找出偏移变化（夏令时切换），每次变化对应一个观测段
+func writeVTimezone(w *icalWriter, loc *time.Location, events []CalendarEvent) {
+	from, to := time.Now(), time.Now()
+	for _, e := range events {
+		if e.Start.Before(from) {
+			from = e.Start
+		}
+		if e.End.After(to) {
+			to = e.End
+		}
+	}
+	from = time.Date(from.Year(), 1, 1, 0, 0, 0, 0, loc)
+	to = time.Date(to.Year()+1, 1, 1, 0, 0, 0, 0, loc)
+
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + loc.String())
+	// 范围起点的偏移作为第一个观测段，之后每次切换一段
+	name, offset := from.Zone()
+	writeObservance(w, observanceKind(from.IsDST()), from.UTC().Add(time.Duration(offset)*time.Second), name, offset, offset)
+	for _, t := range zoneTransitions(from, to) {
+		writeObservance(w, observanceKind(t.isDST), t.at.Add(time.Duration(t.before)*time.Second), t.name, t.before, t.after)
+	}
+	w.line("END:VTIMEZONE")
+}
+
+func observanceKind(isDST bool) string {
+	if isDST {
+		return "DAYLIGHT"
+	}
+	return "STANDARD"
+}
+
+type zoneTransition struct {
+	at            time.Time // UTC 时刻
+	name          string
+	before, after int // 切换前后的 UTC 偏移（秒）
+	isDST         bool
+}
+
+func zoneTransitions(from, to time.Time) []zoneTransition {
+	var transitions []zoneTransition
+	prev := from
+	_, prevOffset := prev.Zone()
+	for t := from.Add(24 * time.Hour); !t.After(to); t = t.Add(24 * time.Hour) {
+		_, offset := t.Zone()
+		if offset != prevOffset {
+			// 二分查找到精确的切换时刻
+			lo, hi := prev, t
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == prevOffset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			name, _ := hi.Zone()
+			transitions = append(transitions, zoneTransition{
+				at:     hi.UTC(),
+				name:   name,
+				before: prevOffset,
+				after:  offset,
+				isDST:  hi.IsDST(),
+			})
+			prevOffset = offset
+		}
+		prev = t
+	}
+	return transitions
+}
+
+// local 为切换前的本地时间（以 UTC 表示的墙上时间）
+func writeObservance(w *icalWriter, kind string, local time.Time, name string, from, to int) {
+	w.line("BEGIN:" + kind)
+	w.line("DTSTART:" + local.UTC().Format("20060102T150405"))
+	w.line("TZOFFSETFROM:" + icalOffset(from))
+	w.line("TZOFFSETTO:" + icalOffset(to))
+	if name != "" {
+		w.line("TZNAME:" + escapeICalText(name))
+	}
+	w.line("END:" + kind)
+}
+
+func icalOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+}
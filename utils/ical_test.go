@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCalendarEvent() CalendarEvent {
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	return CalendarEvent{
+		UID:         "event-1@example.com",
+		Sequence:    2,
+		Summary:     "Meetup; Berlin, 2025",
+		Description: "line one\nline two",
+		Location:    "Alexanderplatz 1",
+		URL:         "https://example.com/events/1",
+		Categories:  []string{"web3", "meetup"},
+		Start:       start,
+		End:         start.Add(2 * time.Hour),
+		Created:     start.Add(-48 * time.Hour),
+		Updated:     start.Add(-24 * time.Hour),
+	}
+}
+
+func TestRenderCalendar(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		location *time.Location
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "UTC",
+			location: nil,
+			contains: []string{
+				"BEGIN:VCALENDAR\r\n",
+				"UID:event-1@example.com\r\n",
+				"DTSTART:20250701T100000Z\r\n",
+				"DTEND:20250701T120000Z\r\n",
+				"DTSTAMP:20250630T100000Z\r\n",
+				"SEQUENCE:2\r\n",
+				`SUMMARY:Meetup\; Berlin\, 2025` + "\r\n",
+				`DESCRIPTION:line one\nline two` + "\r\n",
+				"CATEGORIES:web3,meetup\r\n",
+				"REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n",
+				"END:VCALENDAR\r\n",
+			},
+			excludes: []string{"VTIMEZONE"},
+		},
+		{
+			name:     "Configured timezone",
+			location: berlin,
+			contains: []string{
+				"X-WR-TIMEZONE:Europe/Berlin\r\n",
+				"TZID:Europe/Berlin\r\n",
+				"BEGIN:DAYLIGHT\r\nDTSTART:20250330T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\n",
+				"BEGIN:STANDARD\r\nDTSTART:20251026T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\n",
+				"DTSTART;TZID=Europe/Berlin:20250701T120000\r\n",
+				"DTEND;TZID=Europe/Berlin:20250701T140000\r\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := string(RenderCalendar(&Calendar{
+				ProdID:          "-//Hyperlane//Events//EN",
+				Name:            "Events",
+				Location:        tt.location,
+				RefreshInterval: time.Hour,
+				Events:          []CalendarEvent{testCalendarEvent()},
+			}))
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("output missing %q\n%s", s, out)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(out, s) {
+					t.Errorf("output should not contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestICalLineFolding(t *testing.T) {
+	w := &icalWriter{}
+	w.line("DESCRIPTION:" + strings.Repeat("活动", 40))
+
+	lines := strings.Split(strings.TrimSuffix(w.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected folded lines, got %d", len(lines))
+	}
+	var unfolded strings.Builder
+	for i, l := range lines {
+		if len(l) > 75 {
+			t.Errorf("line %d is %d octets", i, len(l))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(l, " ") {
+				t.Errorf("continuation line %d does not start with a space", i)
+			}
+			l = l[1:]
+		}
+		unfolded.WriteString(l)
+	}
+	if unfolded.String() != "DESCRIPTION:"+strings.Repeat("活动", 40) {
+		t.Errorf("unfolded content mismatch: %s", unfolded.String())
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image type")
+
+// 允许上传的图片类型及对应扩展名
+var imageExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type ImageInfo struct {
+	MimeType string
+	Ext      string
+	Width    int
+	Height   int
+}
+
+// DetectImage 按文件内容（而不是扩展名或客户端声明的类型）识别图片类型，只解析头部获取尺寸
+func DetectImage(data []byte) (*ImageInfo, error) {
+	mimeType := http.DetectContentType(data)
+	ext, ok := imageExts[mimeType]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	return &ImageInfo{MimeType: mimeType, Ext: ext, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// Thumbnail 等比缩放到最长边不超过 maxSide，PNG/GIF 输出 PNG 以保留透明度，其余输出 JPEG
+func Thumbnail(data []byte, maxSide int) ([]byte, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			w, h = maxSide, max(1, h*maxSide/w)
+		} else {
+			w, h = max(1, w*maxSide/h), maxSide
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" {
+		err = png.Encode(&buf, dst)
+		return buf.Bytes(), "image/png", err
+	}
+	err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	return buf.Bytes(), "image/jpeg", err
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodeImage(t *testing.T, w, h int, format string) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+		width    int
+		height   int
+		wantErr  bool
+	}{
+		{name: "PNG", data: encodeImage(t, 40, 30, "png"), mimeType: "image/png", width: 40, height: 30},
+		{name: "JPEG", data: encodeImage(t, 20, 50, "jpeg"), mimeType: "image/jpeg", width: 20, height: 50},
+		{name: "Plain text", data: []byte("hello world"), wantErr: true},
+		{name: "SVG", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), wantErr: true},
+		{name: "Truncated PNG", data: encodeImage(t, 40, 30, "png")[:12], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := DetectImage(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if info.MimeType != tt.mimeType || info.Width != tt.width || info.Height != tt.height {
+				t.Errorf("DetectImage() = %+v", info)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		maxSide  int
+		mimeType string
+		width    int
+		height   int
+	}{
+		{name: "Landscape PNG", data: encodeImage(t, 400, 200, "png"), maxSide: 100, mimeType: "image/png", width: 100, height: 50},
+		{name: "Portrait JPEG", data: encodeImage(t, 200, 400, "jpeg"), maxSide: 100, mimeType: "image/jpeg", width: 50, height: 100},
+		{name: "Small image kept", data: encodeImage(t, 60, 40, "png"), maxSide: 100, mimeType: "image/png", width: 60, height: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, mimeType, err := Thumbnail(tt.data, tt.maxSide)
+			if err != nil {
+				t.Fatalf("Thumbnail() error = %v", err)
+			}
+			info, err := DetectImage(thumb)
+			if err != nil {
+				t.Fatalf("DetectImage(thumbnail) error = %v", err)
+			}
+			if mimeType != tt.mimeType || info.MimeType != tt.mimeType || info.Width != tt.width || info.Height != tt.height {
+				t.Errorf("Thumbnail() = %s %dx%d", mimeType, info.Width, info.Height)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -8,31 +10,71 @@ import (
 	"github.com/spf13/viper"
 )
 
-// JWT 密钥
-var jwtSecret = viper.GetString("jwt.secret")
+// JWT 密钥，由 InitJWT 在启动时设置
+var jwtSecret string
+
+func InitJWT(secret string) {
+	jwtSecret = secret
+}
+
+// 默认有效期，可通过 jwt.accessTtl / jwt.refreshTtl / jwt.loginCodeTtl 配置
+const (
+	defaultAccessTTL    = 15 * time.Minute
+	defaultRefreshTTL   = 30 * 24 * time.Hour
+	defaultLoginCodeTTL = time.Minute
+)
 
 // 结构体定义 JWT 负载
 type Claims struct {
-	Uid         uint     `json:"uid"`
-	Email       string   `json:"email"`
-	Avatar      string   `json:"avatar"`
-	Username    string   `json:"username"`
-	Github      string   `json:"github"`
-	Permissions []string `json:"permissions"`
+	Uid          uint     `json:"uid"`
+	Email        string   `json:"email"`
+	Avatar       string   `json:"avatar"`
+	Username     string   `json:"username"`
+	Github       string   `json:"github"`
+	Permissions  []string `json:"permissions"`
+	TokenVersion uint     `json:"ver"`
 	jwt.RegisteredClaims
 }
 
+func AccessTokenTTL() time.Duration {
+	if ttl := viper.GetDuration("jwt.accessTtl"); ttl > 0 {
+		return ttl
+	}
+	return defaultAccessTTL
+}
+
+func RefreshTokenTTL() time.Duration {
+	if ttl := viper.GetDuration("jwt.refreshTtl"); ttl > 0 {
+		return ttl
+	}
+	return defaultRefreshTTL
+}
+
+func LoginCodeTTL() time.Duration {
+	if ttl := viper.GetDuration("jwt.loginCodeTtl"); ttl > 0 {
+		return ttl
+	}
+	return defaultLoginCodeTTL
+}
+
 // 生成 JWT 令牌
-func GenerateToken(uid uint, email, avatar, username, github string, permissions []string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour * 7)
+func GenerateToken(uid uint, email, avatar, username, github string, permissions []string, tokenVersion uint) (string, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	expirationTime := time.Now().Add(AccessTokenTTL())
 	claims := Claims{
-		Uid:         uid,
-		Email:       email,
-		Avatar:      avatar,
-		Username:    username,
-		Github:      github,
-		Permissions: permissions,
+		Uid:          uid,
+		Email:        email,
+		Avatar:       avatar,
+		Username:     username,
+		Github:       github,
+		Permissions:  permissions,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -59,3 +101,11 @@ func ParseToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
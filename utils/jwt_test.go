@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestGenerateAndParseToken(t *testing.T) {
+	jwtSecret = "test-secret"
+
+	tests := []struct {
+		name         string
+		uid          uint
+		permissions  []string
+		tokenVersion uint
+	}{
+		{
+			name:         "Token without permissions",
+			uid:          1,
+			permissions:  nil,
+			tokenVersion: 0,
+		},
+		{
+			name:         "Token with permissions and version",
+			uid:          42,
+			permissions:  []string{"blog:write", "event:write"},
+			tokenVersion: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := GenerateToken(tt.uid, "a@b.c", "", "user", "", tt.permissions, tt.tokenVersion)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			claims, err := ParseToken(token)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if claims.Uid != tt.uid {
+				t.Errorf("Uid = %v, want %v", claims.Uid, tt.uid)
+			}
+			if claims.TokenVersion != tt.tokenVersion {
+				t.Errorf("TokenVersion = %v, want %v", claims.TokenVersion, tt.tokenVersion)
+			}
+			if !StringSlicesEqual(claims.Permissions, tt.permissions) {
+				t.Errorf("Permissions = %v, want %v", claims.Permissions, tt.permissions)
+			}
+			if claims.ID == "" {
+				t.Errorf("missing jti")
+			}
+		})
+	}
+}
+
+func TestParseTokenWrongSecret(t *testing.T) {
+	jwtSecret = "test-secret"
+	token, err := GenerateToken(1, "a@b.c", "", "user", "", nil, 0)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	jwtSecret = "other-secret"
+	if _, err := ParseToken(token); err == nil {
+		t.Errorf("ParseToken() with wrong secret should fail")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	a, err := GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+	b, _ := GenerateRefreshToken()
+
+	if a == b {
+		t.Errorf("GenerateRefreshToken() returned duplicate tokens")
+	}
+	if HashToken(a) != HashToken(a) {
+		t.Errorf("HashToken() is not deterministic")
+	}
+	if HashToken(a) == HashToken(b) {
+		t.Errorf("HashToken() collision")
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 错误提示的翻译，按错误码索引；缺少的语言或错误码使用 AppError 自带的英文提示
+var messages = map[string]map[string]string{
+	"zh": {
+		CodeInvalidArgument:  "参数错误",
+		CodeValidationFailed: "参数校验失败",
+		CodeUnauthorized:     "请先登录",
+		CodeForbidden:        "没有权限",
+		CodeNotFound:         "资源不存在",
+		CodeConflict:         "操作冲突",
+		CodeTooManyRequests:  "请求过于频繁，请稍后再试",
+		CodeInternal:         "服务器内部错误",
+		CodeUnavailable:      "服务暂不可用",
+
+		"INVALID_CURSOR": "分页游标无效",
+
+		"POST_NOT_FOUND":    "帖子不存在",
+		"NOT_AUTHOR":        "只有作者可以执行此操作",
+		"ALREADY_LIKED":     "已经点过赞了",
+		"ALREADY_FAVORITED": "已经收藏过了",
+
+		"EVENT_NOT_FOUND":        "活动不存在",
+		"REGISTRATION_CLOSED":    "报名已截止",
+		"ALREADY_REGISTERED":     "已经报名",
+		"REGISTRATION_NOT_FOUND": "报名记录不存在",
+		"REGISTRATION_CANCELLED": "报名已取消",
+
+		"CONTENT_NOT_FOUND":     "内容不存在",
+		"INVALID_REVIEW_ACTION": "无效的审核操作",
+		"INVALID_TRANSITION":    "当前状态不允许此操作",
+		"REVIEW_FORBIDDEN":      "没有权限执行此审核操作",
+		"REVIEW_NOTE_REQUIRED":  "退回修改时必须填写意见",
+
+		"CLAIMED_BY_OTHER": "已被其他审核人认领",
+		"CLAIM_NOT_FOUND":  "认领不存在或已过期",
+		"NOT_PENDING":      "内容不在待审核状态",
+
+		"ARTICLE_NOT_FOUND":      "博客不存在",
+		"ARTICLE_ARCHIVED":       "博客已归档",
+		"REVISION_NOT_FOUND":     "修订不存在",
+		"REVISION_NOT_PENDING":   "修订不在待审核状态",
+		"REVISION_NOTE_REQUIRED": "驳回修订时必须填写意见",
+
+		"ROLE_NOT_FOUND":             "角色不存在",
+		"ROLE_EXISTS":                "角色已存在",
+		"PERMISSION_NOT_FOUND":       "权限不存在",
+		"PERMISSION_GROUP_NOT_FOUND": "权限组不存在",
+		"PERMISSION_GROUP_EXISTS":    "权限组已存在",
+		"USER_NOT_FOUND":             "用户不存在",
+
+		"UPLOAD_NOT_FOUND": "文件不存在",
+		"UPLOAD_IN_USE":    "文件仍在使用中",
+	},
+}
+
+// Localize 按 Accept-Language 的优先级查找错误码对应的提示，找不到时返回 fallback
+func Localize(acceptLanguage, code, fallback string) string {
+	for _, lang := range preferredLanguages(acceptLanguage) {
+		if lang == "en" {
+			return fallback
+		}
+		if msg, ok := messages[lang][code]; ok {
+			return msg
+		}
+	}
+	return fallback
+}
+
+// 解析 Accept-Language，返回按权重排序的主语言标签，如 "zh-CN,zh;q=0.9,en;q=0.8" -> [zh zh en]
+func preferredLanguages(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		langs = append(langs, weighted{lang: strings.ToLower(base), q: q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, len(langs))
+	for i, l := range langs {
+		result[i] = l.lang
+	}
+	return result
+}
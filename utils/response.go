@@ -13,11 +13,17 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 	})
 }
 
-// 错误响应
+// 错误响应，code 与 HTTP 状态码一致，error_code 按状态码归类；
+// 有具体错误时应使用 c.Error / RenderError，由 AppError 给出稳定的错误码
 func ErrorResponse(c *gin.Context, statusCode int, message string, data interface{}) {
-	c.JSON(statusCode, gin.H{
-		"code":    500,
-		"message": message,
-		"data":    data,
-	})
+	body := gin.H{
+		"code":       statusCode,
+		"error_code": codeForStatus(statusCode),
+		"message":    message,
+		"data":       data,
+	}
+	if id := c.GetString("request_id"); id != "" {
+		body["request_id"] = id
+	}
+	c.JSON(statusCode, body)
 }
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"regexp"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -93,3 +94,11 @@ func StringSlicesEqual(a, b []string) bool {
 	}
 	return true
 }
+
+// CSVCell 防止 CSV 公式注入：以 = + - @ 或制表符、回车开头的单元格会被表格软件当作公式执行，前面加单引号按文本处理
+func CSVCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -85,3 +85,28 @@ func TestGetGitRank(t *testing.T) {
 		})
 	}
 }
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Plain", in: "Alice", want: "Alice"},
+		{name: "Empty", in: "", want: ""},
+		{name: "Formula", in: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{name: "Plus", in: "+1-555", want: "'+1-555"},
+		{name: "Minus", in: "-2+3", want: "'-2+3"},
+		{name: "At", in: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "Tab", in: "\t=1", want: "'\t=1"},
+		{name: "Inner equals", in: "a=b@example.com", want: "a=b@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CSVCell(tt.in); got != tt.want {
+				t.Errorf("CSVCell(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
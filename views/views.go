@@ -0,0 +1,153 @@
+package views
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"hyperlane/logger"
+	"hyperlane/metrics"
+	"hyperlane/models"
+
+	"github.com/spf13/viper"
+)
+
+// FlushFunc 把一类内容的浏览量增量写入存储，counts 为内容 ID -> 增量
+type FlushFunc func(targetType string, counts map[uint]uint) error
+
+type target struct {
+	typ string
+	id  uint
+}
+
+// Tracker 统计内容详情浏览量：同一访客在 window 内重复访问只计一次，
+// 增量先累积在内存中，由 Flush 批量写入。去重只在单实例内生效
+type Tracker struct {
+	window    time.Duration
+	mu        sync.Mutex
+	seen      map[string]time.Time // 访客+内容 -> 最近一次计数时间
+	pending   map[target]uint
+	flush     FlushFunc
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewTracker(window time.Duration, flush FlushFunc) *Tracker {
+	return &Tracker{
+		window:  window,
+		seen:    make(map[string]time.Time),
+		pending: make(map[target]uint),
+		flush:   flush,
+		now:     time.Now,
+	}
+}
+
+// Record 记录一次浏览，返回是否计入浏览量；viewer 为访客标识（用户 ID 或 IP）
+func (t *Tracker) Record(targetType string, id uint, viewer string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	key := targetType + ":" + viewer + ":" + strconv.FormatUint(uint64(id), 10)
+	if last, ok := t.seen[key]; ok && now.Sub(last) < t.window {
+		metrics.Views.WithLabelValues(targetType, "duplicate").Inc()
+		return false
+	}
+	t.seen[key] = now
+	t.pending[target{typ: targetType, id: id}]++
+	metrics.Views.WithLabelValues(targetType, "counted").Inc()
+	return true
+}
+
+// Flush 写入累积的增量；写入失败的部分放回缓冲区，下次继续写入
+func (t *Tracker) Flush() error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[target]uint)
+	t.mu.Unlock()
+
+	batches := make(map[string]map[uint]uint)
+	for tg, n := range pending {
+		if batches[tg.typ] == nil {
+			batches[tg.typ] = make(map[uint]uint)
+		}
+		batches[tg.typ][tg.id] = n
+	}
+
+	var firstErr error
+	for typ, counts := range batches {
+		if err := t.flush(typ, counts); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			t.restore(typ, counts)
+		}
+	}
+	return firstErr
+}
+
+func (t *Tracker) restore(typ string, counts map[uint]uint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, n := range counts {
+		t.pending[target{typ: typ, id: id}] += n
+	}
+}
+
+// Run 每隔 interval 写入一次，ctx 结束时做最后一次写入后返回
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.Flush(); err != nil {
+				logger.Log.Errorf("flush view counts: %v", err)
+			}
+		case <-ctx.Done():
+			if err := t.Flush(); err != nil {
+				logger.Log.Errorf("flush view counts: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// 清理超出去重窗口的访客记录，避免按 IP 计数时内存无限增长
+func (t *Tracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < time.Minute {
+		return
+	}
+	t.lastSweep = now
+	for key, last := range t.seen {
+		if now.Sub(last) >= t.window {
+			delete(t.seen, key)
+		}
+	}
+}
+
+var current *Tracker
+
+// Init 按 views.dedupWindow 创建默认 Tracker，写入 models
+func Init() {
+	// 写入不跟随 Run 的 ctx，停机时最后一次写入仍能完成
+	current = NewTracker(viper.GetDuration("views.dedupWindow"), func(targetType string, counts map[uint]uint) error {
+		return models.IncrementViewCounts(context.Background(), targetType, counts)
+	})
+}
+
+// Default 返回 Init 创建的 Tracker
+func Default() *Tracker {
+	return current
+}
+
+// Record 使用默认 Tracker 记录浏览，未初始化时不计数
+func Record(targetType string, id uint, viewer string) bool {
+	if current == nil {
+		return false
+	}
+	return current.Record(targetType, id, viewer)
+}
@@ -0,0 +1,87 @@
+package views
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrackerRecord(t *testing.T) {
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	tr := NewTracker(30*time.Minute, nil)
+	tr.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		typ     string
+		id      uint
+		viewer  string
+		want    bool
+	}{
+		{name: "First view", typ: "post", id: 1, viewer: "u:1", want: true},
+		{name: "Repeat within window", advance: 10 * time.Minute, typ: "post", id: 1, viewer: "u:1", want: false},
+		{name: "Another viewer", typ: "post", id: 1, viewer: "ip:10.0.0.1", want: true},
+		{name: "Another post", typ: "post", id: 2, viewer: "u:1", want: true},
+		{name: "Same id, another type", typ: "blog", id: 1, viewer: "u:1", want: true},
+		{name: "Repeat after window", advance: 30 * time.Minute, typ: "post", id: 1, viewer: "u:1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			if got := tr.Record(tt.typ, tt.id, tt.viewer); got != tt.want {
+				t.Errorf("Record() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	want := map[target]uint{{"post", 1}: 3, {"post", 2}: 1, {"blog", 1}: 1}
+	if len(tr.pending) != len(want) {
+		t.Fatalf("pending = %v, want %v", tr.pending, want)
+	}
+	for k, n := range want {
+		if tr.pending[k] != n {
+			t.Errorf("pending[%v] = %d, want %d", k, tr.pending[k], n)
+		}
+	}
+}
+
+func TestTrackerFlush(t *testing.T) {
+	written := make(map[string]map[uint]uint)
+	failing := true
+	tr := NewTracker(time.Minute, func(typ string, counts map[uint]uint) error {
+		if typ == "event" && failing {
+			return errors.New("db down")
+		}
+		written[typ] = counts
+		return nil
+	})
+
+	tr.Record("post", 1, "a")
+	tr.Record("post", 1, "b")
+	tr.Record("event", 7, "a")
+
+	// 写入失败的增量保留到下一次
+	if err := tr.Flush(); err == nil {
+		t.Fatal("Flush() error = nil, want error")
+	}
+	if written["post"][1] != 2 {
+		t.Errorf("post counts = %v, want 1:2", written["post"])
+	}
+	if tr.pending[target{"event", 7}] != 1 || len(tr.pending) != 1 {
+		t.Errorf("pending after failure = %v", tr.pending)
+	}
+
+	failing = false
+	tr.Record("event", 7, "b")
+	if err := tr.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if written["event"][7] != 2 {
+		t.Errorf("event counts = %v, want 7:2", written["event"])
+	}
+	if len(tr.pending) != 0 {
+		t.Errorf("pending after flush = %v", tr.pending)
+	}
+}